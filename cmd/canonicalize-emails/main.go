@@ -0,0 +1,117 @@
+// Command canonicalize-emails rewrites stored emails into the canonical form
+// the service looks them up by, using the same identifier policy and
+// EMAIL_PROVIDER_RULES setting. Replaced addresses are kept in
+// account_email_originals, so rolling back the migration restores them.
+//
+// Accounts whose emails share one canonical form are not touched. They are
+// listed as duplicates and the command exits non-zero until they have been
+// merged. Once nothing is left, it validates the accounts_email_lowercase
+// check, which until then only covers rows written since the migration.
+// Run it with -dry-run first to get the report without writing anything,
+// and before encrypt-accounts, as encrypted emails are not rewritten.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/account"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/auth-service/internal/infra/fieldcrypt"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+	"github.com/teacinema-go/auth-service/internal/services/identifier"
+	"github.com/teacinema-go/core/logger"
+)
+
+const validateLowercaseCheck = "ALTER TABLE accounts VALIDATE CONSTRAINT accounts_email_lowercase"
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing")
+	batchSize := flag.Int("batch-size", 500, "accounts read per query")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("failed to load config:", err)
+	}
+
+	logger.Init(cfg.App.Env)
+
+	// The cipher is only needed to find encrypted accounts that already hold
+	// a canonical email.
+	var cipher *fieldcrypt.Cipher
+	if cfg.FieldEncryption.Enabled {
+		cipher, err = fieldcrypt.NewCipher(cfg.FieldEncryption.Keys, cfg.FieldEncryption.PrimaryKeyID, cfg.FieldEncryption.BlindIndexKey, cfg.FieldEncryption.RetiringBlindIndexKeys)
+		if err != nil {
+			log.Fatal("failed to set up field encryption:", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := postgres.NewPostgresClient(ctx, &cfg.Postgres)
+	if err != nil {
+		log.Fatal("failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	policy := identifier.NewEmailPolicy(cfg.Email.ProviderRules, nil, nil, 0)
+	canonicalize := func(email string) (string, error) {
+		canonical, err := policy.Normalize(valueobject.Identifier(email))
+		return string(canonical), err
+	}
+
+	res, err := account.NewPostgresAccountRepository(sqlc.New(db), cipher).CanonicalizeEmails(ctx, canonicalize, int32(*batchSize), *dryRun)
+	if err != nil {
+		logger.Error("canonicalization stopped with error", "canonicalized", res.Canonicalized, "error", err)
+		stop()
+		db.Close()
+		os.Exit(1)
+	}
+
+	for _, group := range res.Duplicates {
+		ids := make([]string, 0, len(group))
+		for _, id := range group {
+			ids = append(ids, id.ToString())
+		}
+		logger.Warn("accounts share a canonical email", "account_ids", ids)
+	}
+	for _, id := range res.Invalid {
+		logger.Warn("account email is not a valid address", "account_id", id.ToString())
+	}
+
+	logger.Info("account emails canonicalized",
+		"canonicalized", res.Canonicalized,
+		"duplicates", len(res.Duplicates),
+		"invalid", len(res.Invalid),
+		"dry_run", *dryRun,
+	)
+
+	if len(res.Duplicates) > 0 {
+		stop()
+		db.Close()
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		return
+	}
+
+	// Fails while an email that is not a valid address is not lowercase;
+	// fix those by hand and run the command again.
+	if _, err = db.Exec(ctx, validateLowercaseCheck); err != nil {
+		logger.Error("failed to validate the lowercase email check", "error", err)
+		stop()
+		db.Close()
+		os.Exit(1)
+	}
+
+	logger.Info("lowercase email check validated")
+}
@@ -0,0 +1,74 @@
+// Command encrypt-accounts moves plaintext phone and email values and webhook
+// signing secrets into the encrypted columns, re-encrypts values sealed with
+// a retired key and re-indexes accounts indexed with a retiring blind index
+// key. Run it after enabling field encryption and after every change of
+// FIELD_ENCRYPTION_PRIMARY_KEY_ID or FIELD_ENCRYPTION_BLIND_INDEX_KEY; a
+// retired key can be removed from FIELD_ENCRYPTION_KEYS or
+// FIELD_ENCRYPTION_RETIRING_BLIND_INDEX_KEYS once it reports nothing left to
+// do.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/account"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/webhook"
+	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/auth-service/internal/infra/fieldcrypt"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+	"github.com/teacinema-go/core/logger"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("failed to load config:", err)
+	}
+
+	logger.Init(cfg.App.Env)
+
+	if !cfg.FieldEncryption.Enabled {
+		log.Fatal("FIELD_ENCRYPTION_ENABLED must be true")
+	}
+
+	cipher, err := fieldcrypt.NewCipher(cfg.FieldEncryption.Keys, cfg.FieldEncryption.PrimaryKeyID, cfg.FieldEncryption.BlindIndexKey, cfg.FieldEncryption.RetiringBlindIndexKeys)
+	if err != nil {
+		log.Fatal("failed to set up field encryption:", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := postgres.NewPostgresClient(ctx, &cfg.Postgres)
+	if err != nil {
+		log.Fatal("failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	q := sqlc.New(db)
+
+	total, err := account.NewPostgresAccountRepository(q, cipher).EncryptExisting(ctx, cfg.FieldEncryption.BatchSize)
+	if err != nil {
+		logger.Error("encryption stopped with error", "encrypted", total, "error", err)
+		stop()
+		db.Close()
+		os.Exit(1)
+	}
+
+	logger.Info("account identifiers encrypted", "encrypted", total, "key_id", cipher.PrimaryKeyID())
+
+	total, err = webhook.NewPostgresWebhookRepository(q, cipher).EncryptExisting(ctx, cfg.FieldEncryption.BatchSize)
+	if err != nil {
+		logger.Error("encryption stopped with error", "encrypted", total, "error", err)
+		stop()
+		db.Close()
+		os.Exit(1)
+	}
+
+	logger.Info("webhook secrets encrypted", "encrypted", total, "key_id", cipher.PrimaryKeyID())
+}
@@ -5,31 +5,70 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/teacinema-go/auth-service/internal/auth/repositories/account"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/apiKey"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/authEvent"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/client"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/impersonation"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/outbox"
 	"github.com/teacinema-go/auth-service/internal/auth/repositories/refreshToken"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/role"
+	"github.com/teacinema-go/auth-service/internal/auth/repositories/webhook"
 	"github.com/teacinema-go/auth-service/internal/auth/services"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
 	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/auth-service/internal/infra/certs"
+	"github.com/teacinema-go/auth-service/internal/infra/eventbus"
+	"github.com/teacinema-go/auth-service/internal/infra/fieldcrypt"
+	"github.com/teacinema-go/auth-service/internal/infra/metrics"
 	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres"
 	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
 	"github.com/teacinema-go/auth-service/internal/infra/storage/redis"
+	"github.com/teacinema-go/auth-service/internal/infra/tracing"
+	"github.com/teacinema-go/auth-service/internal/services/audit"
+	"github.com/teacinema-go/auth-service/internal/services/challenge"
+	"github.com/teacinema-go/auth-service/internal/services/health"
+	"github.com/teacinema-go/auth-service/internal/services/identifier"
+	outboxRelay "github.com/teacinema-go/auth-service/internal/services/outbox"
+	"github.com/teacinema-go/auth-service/internal/services/ratelimit"
+	"github.com/teacinema-go/auth-service/internal/services/revocation"
 	"github.com/teacinema-go/auth-service/internal/services/txmanager"
+	webhookDispatcher "github.com/teacinema-go/auth-service/internal/services/webhook"
+	"github.com/teacinema-go/auth-service/internal/transport/gateway"
 	"github.com/teacinema-go/auth-service/internal/transport/grpc/handlers"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/interceptors"
 	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
+	adminv1 "github.com/teacinema-go/contracts/gen/go/admin/v1"
 	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
 	"github.com/teacinema-go/core/logger"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type App struct {
-	cfg         *config.Config
-	grpcServer  *grpc.Server
-	db          *pgxpool.Pool
-	redisClient *redis.Client
+	cfg            *config.Config
+	grpcServer     *grpc.Server
+	httpServer     *http.Server
+	gatewayServer  *http.Server
+	db             *pgxpool.Pool
+	redisClient    *redis.Client
+	eventPublisher EventPublisher
+}
+
+type EventPublisher interface {
+	outboxRelay.EventPublisher
+	Close() error
 }
 
 func New(cfg *config.Config) *App {
@@ -41,12 +80,22 @@ func New(cfg *config.Config) *App {
 func (a *App) Run() error {
 	ctx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(ctx, &a.cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("failed to flush traces", "error", err)
+		}
+	}()
+
 	db, err := postgres.NewPostgresClient(ctx, &a.cfg.Postgres)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	a.db = db
-	sqlcQuerier := sqlc.New(db)
+	sqlcQuerier := sqlc.New(txmanager.NewDB(db))
 
 	logger.Info("database connection established")
 
@@ -57,19 +106,160 @@ func (a *App) Run() error {
 	a.redisClient = redisClient
 	logger.Info("redis connection established")
 
-	a.grpcServer = grpc.NewServer()
+	appMetrics := metrics.New()
+	if err = appMetrics.Register(metrics.NewPostgresPoolCollector(db)); err != nil {
+		return fmt.Errorf("failed to register postgres metrics: %w", err)
+	}
+	if err = appMetrics.Register(metrics.NewRedisPoolCollector(redisClient)); err != nil {
+		return fmt.Errorf("failed to register redis metrics: %w", err)
+	}
+
+	var fieldCipher *fieldcrypt.Cipher
+	if a.cfg.FieldEncryption.Enabled {
+		fieldCipher, err = fieldcrypt.NewCipher(a.cfg.FieldEncryption.Keys, a.cfg.FieldEncryption.PrimaryKeyID, a.cfg.FieldEncryption.BlindIndexKey, a.cfg.FieldEncryption.RetiringBlindIndexKeys)
+		if err != nil {
+			return fmt.Errorf("failed to set up field encryption: %w", err)
+		}
+	}
 
 	txManager := txmanager.NewPostgresTxManager(db)
-	postgresAccountRepo := account.NewPostgresAccountRepository(sqlcQuerier)
+	postgresAccountRepo := account.NewPostgresAccountRepository(sqlcQuerier, fieldCipher)
 	postgresRefreshTokenRepo := refreshToken.NewPostgresRefreshTokenRepository(sqlcQuerier)
+	postgresClientRepo := client.NewPostgresClientRepository(sqlcQuerier)
+	postgresApiKeyRepo := apiKey.NewPostgresApiKeyRepository(sqlcQuerier)
+	postgresRoleRepo := role.NewPostgresRoleRepository(sqlcQuerier)
+	postgresImpersonationRepo := impersonation.NewPostgresImpersonationRepository(sqlcQuerier)
+	postgresAuthEventRepo := authEvent.NewPostgresAuthEventRepository(sqlcQuerier)
+	postgresOutboxRepo := outbox.NewPostgresOutboxRepository(sqlcQuerier)
+	postgresWebhookRepo := webhook.NewPostgresWebhookRepository(sqlcQuerier, fieldCipher)
+
+	retiringSecretKeys, err := token.ParseKeys(a.cfg.App.RetiringSecretKeys)
+	if err != nil {
+		return fmt.Errorf("failed to parse retiring secret keys: %w", err)
+	}
+	secretKeys, err := token.NewKeys(token.Key{ID: a.cfg.App.SecretKeyID, Secret: a.cfg.App.SecretKey}, retiringSecretKeys)
+	if err != nil {
+		return fmt.Errorf("failed to set up secret keys: %w", err)
+	}
 
-	authService := services.NewAuthService(postgresAccountRepo, postgresRefreshTokenRepo, redisClient, txManager, a.cfg.App.SecretKey)
+	auditRecorder := audit.NewPostgresAuditRecorder(postgresAuthEventRepo, a.cfg.Audit.IdentifierHashKey)
+	revocationList := revocation.NewList(redisClient)
+	authService := services.NewAuthService(services.Deps{
+		AccountRepo:       postgresAccountRepo,
+		RefreshTokenRepo:  postgresRefreshTokenRepo,
+		ClientRepo:        postgresClientRepo,
+		ApiKeyRepo:        postgresApiKeyRepo,
+		RoleRepo:          postgresRoleRepo,
+		ImpersonationRepo: postgresImpersonationRepo,
+		AuthEventRepo:     postgresAuthEventRepo,
+		OutboxRepo:        postgresOutboxRepo,
+		WebhookRepo:       postgresWebhookRepo,
+		AuditRecorder:     auditRecorder,
+		Metrics:           appMetrics,
+		Cache:             redisClient,
+		TxManager:         txManager,
+		RevocationList:    revocationList,
+		RevocationEvents:  a.cfg.Revocation.EventsEnabled,
+		SecretKeys:        secretKeys,
+		Issuer:            a.cfg.App.Issuer,
+	})
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		interceptors.Metrics(appMetrics),
+		interceptors.RequestInfo(),
+		interceptors.Logging(),
+		interceptors.Recovery(),
+		interceptors.Errors(a.cfg.App.ErrorCompat),
+	}
+	var limiter *ratelimit.Limiter
+	if a.cfg.RateLimit.Enabled {
+		rules, err := ratelimit.ParseRules(a.cfg.RateLimit.Rules)
+		if err != nil {
+			return fmt.Errorf("failed to parse rate limit rules: %w", err)
+		}
+		limiter = ratelimit.NewLimiter(redisClient, rules, a.cfg.RateLimit.FailOpen)
+		unaryInterceptors = append(unaryInterceptors, interceptors.RateLimit(limiter.WithKeys(ratelimit.KeyIP)))
+	}
+	unaryInterceptors = append(unaryInterceptors,
+		interceptors.Auth(
+			authService,
+			authv1.AuthService_SendOtp_FullMethodName,
+			authv1.AuthService_VerifyOtp_FullMethodName,
+			authv1.AuthService_Refresh_FullMethodName,
+			authv1.AuthService_Logout_FullMethodName,
+			authv1.AuthService_ClientCredentials_FullMethodName,
+			healthv1.Health_Check_FullMethodName,
+		),
+	)
+	if limiter != nil {
+		unaryInterceptors = append(unaryInterceptors, interceptors.RateLimit(limiter.WithKeys(ratelimit.KeyClient)))
+	}
 
-	authHandler := handlers.NewAuthHandler(authService)
+	serverOptions := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+	}
+
+	var certReloader *certs.Reloader
+	if a.cfg.TLS.Enabled {
+		certReloader, err = certs.NewReloader(&a.cfg.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to load tls certificates: %w", err)
+		}
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(certReloader.ServerConfig())))
+		logger.Info("tls enabled", "mutual", a.cfg.TLS.ClientCAFile != "")
+	}
+
+	a.grpcServer = grpc.NewServer(serverOptions...)
+
+	phonePolicy, err := identifier.NewPhonePolicy(a.cfg.Phone.AllowedCountries, a.cfg.Phone.DeniedCountries, a.cfg.Phone.BlockedTypes)
+	if err != nil {
+		return fmt.Errorf("failed to build phone policy: %w", err)
+	}
+	emailPolicy, err := newEmailPolicy(&a.cfg.Email)
+	if err != nil {
+		return fmt.Errorf("failed to build email policy: %w", err)
+	}
+	identifierPolicy := identifier.NewPolicy(phonePolicy, emailPolicy)
+
+	var challengeGate handlers.ChallengeGate
+	if a.cfg.Challenge.Enabled {
+		challengeGate = newChallengeGate(&a.cfg.Challenge, redisClient)
+	}
+
+	authHandler := handlers.NewAuthHandler(authService, auditRecorder, identifierPolicy, challengeGate)
 	accountHandler := handlers.NewAccountHandler(authService)
+	adminHandler := handlers.NewAdminHandler(authService)
 
 	authv1.RegisterAuthServiceServer(a.grpcServer, authHandler)
 	accountv1.RegisterAccountServiceServer(a.grpcServer, accountHandler)
+	adminv1.RegisterAdminServiceServer(a.grpcServer, adminHandler)
+
+	trustedProxies := make([]netip.Prefix, 0, len(a.cfg.Gateway.TrustedProxies))
+	for _, cidr := range a.cfg.Gateway.TrustedProxies {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		trustedProxies = append(trustedProxies, prefix.Masked())
+	}
+
+	gw := gateway.New(authHandler, accountHandler, interceptors.Chain(unaryInterceptors...), gateway.CORSConfig{
+		AllowedOrigins:   a.cfg.Gateway.CORSAllowedOrigins,
+		AllowedHeaders:   a.cfg.Gateway.CORSAllowedHeaders,
+		AllowCredentials: a.cfg.Gateway.CORSAllowCredentials,
+		MaxAge:           a.cfg.Gateway.CORSMaxAge,
+	}, gateway.SessionConfig{
+		Enabled:      a.cfg.Gateway.SessionCookies,
+		CookieDomain: a.cfg.Gateway.CookieDomain,
+		SameSite:     a.cfg.Gateway.CookieSameSite,
+	}, trustedProxies)
+
+	healthChecker := health.NewChecker(map[string]health.Pinger{
+		"postgres": db,
+		"redis":    redisClient,
+	}, a.cfg.Health.CheckInterval, a.cfg.Health.CheckTimeout)
+	healthChecker.Register(a.grpcServer)
 
 	grpcAddr := fmt.Sprintf(":%d", a.cfg.App.Port)
 	lis, err := net.Listen("tcp", grpcAddr)
@@ -77,9 +267,56 @@ func (a *App) Run() error {
 		return fmt.Errorf("failed to listen on gRPC port: %w", err)
 	}
 
+	busPublisher, err := newEventPublisher(&a.cfg.Outbox)
+	if err != nil {
+		return fmt.Errorf("failed to create event publisher: %w", err)
+	}
+	eventPublisher := eventbus.NewMultiPublisher(
+		busPublisher,
+		eventbus.NewWebhookPublisher(postgresWebhookRepo, a.cfg.Outbox.EventSource),
+	)
+	a.eventPublisher = eventPublisher
+
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	var jobs sync.WaitGroup
+	retentionJob := audit.NewRetentionJob(postgresAuthEventRepo, a.cfg.Audit.RetentionPeriod, a.cfg.Audit.CleanupInterval)
+	jobs.Go(func() { retentionJob.Run(jobsCtx) })
+	relay := outboxRelay.NewRelay(postgresOutboxRepo, eventPublisher, a.cfg.Outbox.BatchSize, a.cfg.Outbox.PollInterval)
+	jobs.Go(func() { relay.Run(jobsCtx) })
+	dispatcher := webhookDispatcher.NewDispatcher(postgresWebhookRepo, webhookDispatcher.NewHTTPClient(a.cfg.Webhook.Timeout), a.cfg.Webhook.MaxAttempts, a.cfg.Webhook.BatchSize, a.cfg.Webhook.PollInterval)
+	jobs.Go(func() { dispatcher.Run(jobsCtx) })
+	jobs.Go(func() { healthChecker.Run(jobsCtx) })
+	if certReloader != nil {
+		jobs.Go(func() { certReloader.Run(jobsCtx) })
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	a.httpServer = newHTTPServer(a.cfg.App.MetricsPort, appMetrics, healthChecker)
+	go func() {
+		logger.Info("starting HTTP server", "port", a.cfg.App.MetricsPort)
+		if err := a.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server error", "error", err)
+			quit <- syscall.SIGTERM
+		}
+	}()
+
+	a.gatewayServer = &http.Server{
+		Addr:              fmt.Sprintf(":%d", a.cfg.Gateway.Port),
+		Handler:           gw.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logger.Info("starting HTTP gateway", "port", a.cfg.Gateway.Port)
+		if err := a.gatewayServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP gateway error", "error", err)
+			quit <- syscall.SIGTERM
+		}
+	}()
+
 	go func() {
 		logger.Info("starting gRPC server", "port", a.cfg.App.Port)
 		if err = a.grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
@@ -92,9 +329,36 @@ func (a *App) Run() error {
 	logger.Info("received shutdown signal", "signal", sig.String())
 	logger.Info("shutting down server...")
 
+	healthChecker.Shutdown()
+	if a.cfg.Health.DrainDelay > 0 {
+		logger.Info("waiting for load balancers to drain", "delay", a.cfg.Health.DrainDelay)
+		time.Sleep(a.cfg.Health.DrainDelay)
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := a.gatewayServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to stop HTTP gateway", "error", err)
+	}
+	logger.Info("HTTP gateway stopped")
+
 	a.grpcServer.GracefulStop()
 	logger.Info("gRPC server stopped")
 
+	if err := a.httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to stop HTTP server", "error", err)
+	}
+
+	stopJobs()
+	jobs.Wait()
+	logger.Info("background jobs stopped")
+
+	if a.eventPublisher != nil {
+		err := a.eventPublisher.Close()
+		if err != nil {
+			logger.Error("failed to close event publisher", "error", err)
+		}
+	}
+
 	if a.db != nil {
 		a.db.Close()
 		logger.Info("database connection closed")
@@ -111,3 +375,65 @@ func (a *App) Run() error {
 	logger.Info("server stopped gracefully")
 	return nil
 }
+
+func newEventPublisher(cfg *config.Outbox) (EventPublisher, error) {
+	switch cfg.Publisher {
+	case "file":
+		return eventbus.NewFilePublisher(cfg.FilePath)
+	case "kafka":
+		return eventbus.NewKafkaPublisher(cfg), nil
+	case "nats":
+		return eventbus.NewNatsPublisher(cfg)
+	default:
+		return eventbus.NewLogPublisher(), nil
+	}
+}
+
+func newEmailPolicy(cfg *config.Email) (*identifier.EmailPolicy, error) {
+	var disposableDomains []string
+	if cfg.DisposableDomainsFile != "" {
+		domains, err := identifier.LoadDomainList(cfg.DisposableDomainsFile)
+		if err != nil {
+			return nil, err
+		}
+		disposableDomains = domains
+	}
+
+	var mxResolver identifier.MXResolver
+	if cfg.MXCheck {
+		mxResolver = net.DefaultResolver
+	}
+
+	return identifier.NewEmailPolicy(cfg.ProviderRules, disposableDomains, mxResolver, cfg.MXTimeout), nil
+}
+
+func newChallengeGate(cfg *config.Challenge, redisClient *redis.Client) *challenge.Gate {
+	var captcha challenge.Verifier
+	if cfg.CaptchaProvider != "none" {
+		endpoint := cfg.CaptchaVerifyURL
+		if endpoint == "" {
+			endpoint = challenge.CaptchaEndpoints[cfg.CaptchaProvider]
+		}
+		captcha = challenge.NewCaptchaVerifier(cfg.CaptchaTimeout, endpoint, cfg.CaptchaSecret, cfg.CaptchaMinScore)
+	}
+
+	var pow *challenge.ProofOfWork
+	if cfg.PowDifficulty > 0 {
+		pow = challenge.NewProofOfWork(redisClient, cfg.PowSecret, cfg.PowDifficulty, cfg.PowTTL)
+	}
+
+	return challenge.NewGate(redisClient, captcha, cfg.CaptchaProvider, pow, cfg.HighRiskPrefixes, cfg.BurstLimit, cfg.BurstPeriod, cfg.KnownIPTTL)
+}
+
+func newHTTPServer(port int, appMetrics *metrics.Metrics, healthChecker *health.Checker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", appMetrics.Handler())
+	mux.Handle("GET /healthz", healthChecker.LivenessHandler())
+	mux.Handle("GET /readyz", healthChecker.ReadinessHandler())
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
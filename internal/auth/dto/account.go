@@ -12,7 +12,17 @@ type CreateAccountParams struct {
 }
 
 type Tokens struct {
-	AccessToken  string
-	RefreshToken string
-	ExpiresIn    int32
+	AccessToken      string
+	RefreshToken     string
+	ExpiresIn        int32
+	RefreshExpiresIn int32
+}
+
+// EmailCanonicalization reports a canonicalize-emails run. Each duplicate
+// group holds accounts whose emails share one canonical form and have to be
+// merged by hand.
+type EmailCanonicalization struct {
+	Canonicalized int
+	Duplicates    [][]valueobject.ID
+	Invalid       []valueobject.ID
 }
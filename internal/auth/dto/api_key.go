@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type CreateApiKeyParams struct {
+	ID        valueobject.ID
+	AccountID valueobject.ID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+type CreatedApiKey struct {
+	ApiKey *entities.ApiKey
+	Key    string
+}
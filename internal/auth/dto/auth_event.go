@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+// AuthEvent carries the canonical identifier; the recorder stores only a
+// keyed hash of it.
+type AuthEvent struct {
+	Type       valueobject.AuthEventType
+	AccountID  *valueobject.ID
+	Identifier valueobject.Identifier
+	ClientID   string
+	Outcome    valueobject.AuthEventOutcome
+	ErrorCode  string
+}
+
+type CreateAuthEventParams struct {
+	ID             valueobject.ID
+	Type           valueobject.AuthEventType
+	AccountID      *valueobject.ID
+	IdentifierHash string
+	IP             string
+	UserAgent      string
+	ClientID       string
+	Outcome        valueobject.AuthEventOutcome
+	ErrorCode      string
+}
+
+type ListAuthEventsParams struct {
+	AccountID       *valueobject.ID
+	From            *time.Time
+	To              *time.Time
+	CursorCreatedAt *time.Time
+	CursorID        *valueobject.ID
+	PageSize        int32
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type ChallengeRequest struct {
+	IP             string
+	Identifier     valueobject.Identifier
+	IdentifierType valueobject.IdentifierType
+	Type           valueobject.ChallengeType
+	Response       string
+}
+
+// Challenge tells the client which challenges it may solve to proceed.
+type Challenge struct {
+	Types           []valueobject.ChallengeType
+	CaptchaProvider string
+	PowChallenge    string
+	PowDifficulty   int
+}
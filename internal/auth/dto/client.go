@@ -0,0 +1,14 @@
+package dto
+
+type ClientCredentials struct {
+	ClientID        string
+	ClientSecret    string
+	ClientAssertion string
+	Scopes          []string
+}
+
+type ClientAccessToken struct {
+	AccessToken string
+	ExpiresIn   int32
+	Scopes      []string
+}
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type CreateImpersonationParams struct {
+	ID              valueobject.ID
+	ActorAccountID  valueobject.ID
+	TargetAccountID valueobject.ID
+	ClientID        string
+	Reason          string
+	ExpiresAt       time.Time
+}
+
+type ImpersonationToken struct {
+	AccessToken string
+	ExpiresIn   int32
+}
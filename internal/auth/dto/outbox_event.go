@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type CreateOutboxEventParams struct {
+	ID            valueobject.ID
+	AggregateType string
+	AggregateID   valueobject.ID
+	EventType     string
+	Payload       json.RawMessage
+}
+
+type OutboxEventFailure struct {
+	ID            valueobject.ID
+	NextAttemptAt time.Time
+	LastError     string
+}
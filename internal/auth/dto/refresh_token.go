@@ -9,6 +9,7 @@ import (
 type CreateRefreshTokenParams struct {
 	ID        uuid.UUID `json:"id"`
 	AccountID uuid.UUID `json:"account_id"`
+	ClientID  string    `json:"client_id"`
 	TokenHash string    `json:"token_hash"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type TokenIntrospection struct {
+	Active    bool
+	Subject   string
+	ClientID  string
+	Scopes    []string
+	TokenID   string
+	SessionID string
+	ActorID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
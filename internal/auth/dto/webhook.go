@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type CreateWebhookSubscriptionParams struct {
+	ID         valueobject.ID
+	URL        string
+	EventTypes []string
+	Secret     string
+}
+
+type CreatedWebhookSubscription struct {
+	Subscription *entities.WebhookSubscription
+	Secret       string
+}
+
+type CreateWebhookDeliveryParams struct {
+	ID             valueobject.ID
+	SubscriptionID valueobject.ID
+	EventID        valueobject.ID
+	EventType      string
+	Payload        json.RawMessage
+}
+
+type WebhookDeliveryFailure struct {
+	ID            valueobject.ID
+	Status        valueobject.WebhookDeliveryStatus
+	NextAttemptAt time.Time
+	StatusCode    *int32
+	Error         string
+}
+
+type CreateWebhookDeliveryAttemptParams struct {
+	ID         valueobject.ID
+	DeliveryID valueobject.ID
+	StatusCode *int32
+	Error      string
+	Duration   time.Duration
+}
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type ApiKey struct {
+	ID         valueobject.ID `json:"id"`
+	AccountID  valueobject.ID `json:"account_id"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`
+	KeyHash    string         `json:"key_hash"`
+	Scopes     []string       `json:"scopes"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type AuthEvent struct {
+	ID             valueobject.ID               `json:"id"`
+	Type           valueobject.AuthEventType    `json:"event_type"`
+	AccountID      *valueobject.ID              `json:"account_id"`
+	IdentifierHash *string                      `json:"identifier_hash"`
+	IP             *string                      `json:"ip"`
+	UserAgent      *string                      `json:"user_agent"`
+	ClientID       *string                      `json:"client_id"`
+	Outcome        valueobject.AuthEventOutcome `json:"outcome"`
+	ErrorCode      *string                      `json:"error_code"`
+	CreatedAt      time.Time                    `json:"created_at"`
+}
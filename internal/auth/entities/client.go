@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"slices"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type Client struct {
+	ID              string                  `json:"id"`
+	Name            string                  `json:"name"`
+	Type            valueobject.ClientType  `json:"type"`
+	GrantTypes      []valueobject.GrantType `json:"grant_types"`
+	Scopes          []string                `json:"scopes"`
+	AccessTokenTTL  *time.Duration          `json:"access_token_ttl"`
+	RefreshTokenTTL *time.Duration          `json:"refresh_token_ttl"`
+	SecretHash      *string                 `json:"secret_hash"`
+	PublicKey       *string                 `json:"public_key"`
+	CreatedAt       time.Time               `json:"created_at"`
+	UpdatedAt       time.Time               `json:"updated_at"`
+}
+
+// AllowsGrantType never allows public clients to use grants that
+// authenticate the client itself, since they cannot keep a secret.
+func (c *Client) AllowsGrantType(grantType valueobject.GrantType) bool {
+	if grantType == valueobject.GrantTypeClientCredentials && c.Type != valueobject.ClientTypeConfidential {
+		return false
+	}
+
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+func (c *Client) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type OutboxEvent struct {
+	ID            valueobject.ID  `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   valueobject.ID  `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int32           `json:"attempts"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"slices"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type Principal struct {
+	Subject   string          `json:"subject"`
+	AccountID *valueobject.ID `json:"account_id"`
+	ClientID  string          `json:"client_id"`
+	ApiKeyID  *valueobject.ID `json:"api_key_id"`
+	ActorID   *valueobject.ID `json:"actor_id"`
+	Scopes    []string        `json:"scopes"`
+}
+
+func (p *Principal) HasPermissions(permissions ...valueobject.Permission) bool {
+	for _, permission := range permissions {
+		if !slices.Contains(p.Scopes, string(permission)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -10,6 +10,7 @@ import (
 type RefreshToken struct {
 	ID        valueobject.ID `json:"id"`
 	AccountID uuid.UUID      `json:"account_id"`
+	ClientID  *string        `json:"client_id"`
 	TokenHash string         `json:"token_hash"`
 	ExpiresAt time.Time      `json:"expires_at"`
 	CreatedAt time.Time      `json:"created_at"`
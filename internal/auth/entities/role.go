@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type Role struct {
+	Name        valueobject.Role         `json:"name"`
+	Description string                   `json:"description"`
+	Permissions []valueobject.Permission `json:"permissions"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+type Permission struct {
+	Name        valueobject.Permission `json:"name"`
+	Description string                 `json:"description"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type WebhookSubscription struct {
+	ID         valueobject.ID `json:"id"`
+	URL        string         `json:"url"`
+	EventTypes []string       `json:"event_types"`
+	Secret     string         `json:"-"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             valueobject.ID                    `json:"id"`
+	SubscriptionID valueobject.ID                    `json:"subscription_id"`
+	EventID        valueobject.ID                    `json:"event_id"`
+	EventType      string                            `json:"event_type"`
+	Payload        json.RawMessage                   `json:"payload"`
+	Status         valueobject.WebhookDeliveryStatus `json:"status"`
+	Attempts       int32                             `json:"attempts"`
+	NextAttemptAt  time.Time                         `json:"next_attempt_at"`
+	LastStatusCode *int32                            `json:"last_status_code"`
+	LastError      *string                           `json:"last_error"`
+	DeliveredAt    *time.Time                        `json:"delivered_at"`
+	CreatedAt      time.Time                         `json:"created_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         valueobject.ID `json:"id"`
+	DeliveryID valueobject.ID `json:"delivery_id"`
+	StatusCode *int32         `json:"status_code"`
+	Error      *string        `json:"error"`
+	Duration   time.Duration  `json:"duration"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"slices"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+const AggregateTypeAccount = "account"
+
+const (
+	TypeAccountCreated     = "AccountCreated"
+	TypeAccountRoleChanged = "AccountRoleChanged"
+	TypeSessionRevoked     = "SessionRevoked"
+	TypeAccessTokenRevoked = "AccessTokenRevoked"
+)
+
+var Types = []string{
+	TypeAccountCreated,
+	TypeAccountRoleChanged,
+	TypeSessionRevoked,
+	TypeAccessTokenRevoked,
+}
+
+type AccountCreated struct {
+	AccountID      string                     `json:"account_id"`
+	IdentifierType valueobject.IdentifierType `json:"identifier_type"`
+	Role           valueobject.Role           `json:"role"`
+	ClientID       string                     `json:"client_id"`
+	OccurredAt     time.Time                  `json:"occurred_at"`
+}
+
+type AccountRoleChanged struct {
+	AccountID  string           `json:"account_id"`
+	Role       valueobject.Role `json:"role"`
+	OccurredAt time.Time        `json:"occurred_at"`
+}
+
+type SessionRevoked struct {
+	AccountID  string    `json:"account_id"`
+	SessionID  string    `json:"session_id"`
+	ClientID   string    `json:"client_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// AccessTokenRevoked mirrors an entry of the access token revocation list so
+// other services can cache it until ExpiresAt. It revokes the token TokenID
+// of the account or, without one, every token of the session or account
+// issued at or before NotBefore.
+type AccessTokenRevoked struct {
+	AccountID  string     `json:"account_id"`
+	TokenID    string     `json:"token_id,omitempty"`
+	SessionID  string     `json:"session_id,omitempty"`
+	NotBefore  *time.Time `json:"not_before,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+func IsKnownType(eventType string) bool {
+	return slices.Contains(Types, eventType)
+}
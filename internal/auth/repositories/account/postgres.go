@@ -2,32 +2,93 @@ package account
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/entities"
 	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/fieldcrypt"
 	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+	"github.com/teacinema-go/core/logger"
 )
 
+const (
+	phoneField = "phone"
+	emailField = "email"
+)
+
+const uniqueViolation = "23505"
+
+// PostgresAccountRepository stores phone and email encrypted, with a blind
+// index for lookups, when it has a cipher. Rows written before encryption was
+// enabled keep their plaintext columns until EncryptExisting moves them, and
+// stay readable and findable meanwhile.
 type PostgresAccountRepository struct {
-	q sqlc.Querier
+	q      sqlc.Querier
+	cipher *fieldcrypt.Cipher
 }
 
-func NewPostgresAccountRepository(q sqlc.Querier) *PostgresAccountRepository {
-	return &PostgresAccountRepository{q: q}
+// NewPostgresAccountRepository accepts a nil cipher to store identifiers in
+// plaintext.
+func NewPostgresAccountRepository(q sqlc.Querier, cipher *fieldcrypt.Cipher) *PostgresAccountRepository {
+	return &PostgresAccountRepository{
+		q:      q,
+		cipher: cipher,
+	}
 }
 
+// CreateAccount must run in a transaction. The identifier check spans the
+// plaintext columns and every blind index key, so an account created while
+// older rows are still being encrypted or re-indexed cannot duplicate one of
+// them.
 func (r *PostgresAccountRepository) CreateAccount(ctx context.Context, arg dto.CreateAccountParams) error {
 	param := sqlc.CreateAccountParams{
-		ID:    arg.ID.ToUUID(),
-		Email: arg.Email,
-		Phone: arg.Phone,
-		Role:  string(arg.Role),
+		ID:          arg.ID.ToUUID(),
+		Email:       arg.Email,
+		Phone:       arg.Phone,
+		Role:        string(arg.Role),
+		LookupPhone: arg.Phone,
+		LookupEmail: arg.Email,
+	}
+
+	if r.cipher != nil {
+		fields, err := r.encryptIdentifiers(param.ID, arg.Phone, arg.Email)
+		if err != nil {
+			return err
+		}
+		param.Phone, param.Email = nil, nil
+		param.PhoneEncrypted, param.PhoneIndex = fields.PhoneEncrypted, fields.PhoneIndex
+		param.EmailEncrypted, param.EmailIndex = fields.EmailEncrypted, fields.EmailIndex
+		param.EncryptionKeyID, param.IndexKeyID = fields.EncryptionKeyID, fields.IndexKeyID
+		if arg.Phone != nil {
+			param.PhoneIndexes = r.cipher.BlindIndexes(phoneField, *arg.Phone)
+		}
+		if arg.Email != nil {
+			param.EmailIndexes = r.cipher.BlindIndexes(emailField, *arg.Email)
+		}
+	}
+
+	if arg.Phone != nil {
+		if err := r.q.LockAccountIdentifier(ctx, lockKey(phoneField, *arg.Phone)); err != nil {
+			return fmt.Errorf("failed to lock phone: %w", err)
+		}
 	}
+	if arg.Email != nil {
+		if err := r.q.LockAccountIdentifier(ctx, lockKey(emailField, *arg.Email)); err != nil {
+			return fmt.Errorf("failed to lock email: %w", err)
+		}
+	}
+
 	_, err := r.q.CreateAccount(ctx, param)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -40,26 +101,32 @@ func (r *PostgresAccountRepository) CreateAccount(ctx context.Context, arg dto.C
 
 func (r *PostgresAccountRepository) GetAccountByEmail(ctx context.Context, email valueobject.Identifier) (*entities.Account, error) {
 	strEmail := string(email)
-	acc, err := r.q.GetAccountByEmail(ctx, &strEmail)
+	acc, err := r.q.GetAccountByEmail(ctx, sqlc.GetAccountByEmailParams{
+		EmailIndexes: r.blindIndexes(emailField, strEmail),
+		Email:        &strEmail,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, appErrors.ErrAccountNotFound
 		}
 		return nil, err
 	}
-	return mapSqlcAccount(acc)
+	return r.mapSqlcAccount(acc)
 }
 
 func (r *PostgresAccountRepository) GetAccountByPhone(ctx context.Context, phone valueobject.Identifier) (*entities.Account, error) {
 	strPhone := string(phone)
-	acc, err := r.q.GetAccountByPhone(ctx, &strPhone)
+	acc, err := r.q.GetAccountByPhone(ctx, sqlc.GetAccountByPhoneParams{
+		PhoneIndexes: r.blindIndexes(phoneField, strPhone),
+		Phone:        &strPhone,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, appErrors.ErrAccountNotFound
 		}
 		return nil, err
 	}
-	return mapSqlcAccount(acc)
+	return r.mapSqlcAccount(acc)
 }
 
 func (r *PostgresAccountRepository) GetAccountByID(ctx context.Context, accountID valueobject.ID) (*entities.Account, error) {
@@ -71,30 +138,283 @@ func (r *PostgresAccountRepository) GetAccountByID(ctx context.Context, accountI
 		return nil, err
 	}
 
-	return mapSqlcAccount(acc)
+	return r.mapSqlcAccount(acc)
 }
 
 func (r *PostgresAccountRepository) AccountExistsByEmail(ctx context.Context, email valueobject.Identifier) (bool, error) {
 	strEmail := string(email)
-	return r.q.AccountExistsByEmail(ctx, &strEmail)
+	return r.q.AccountExistsByEmail(ctx, sqlc.AccountExistsByEmailParams{
+		EmailIndexes: r.blindIndexes(emailField, strEmail),
+		Email:        &strEmail,
+	})
 }
 
 func (r *PostgresAccountRepository) AccountExistsByPhone(ctx context.Context, phone valueobject.Identifier) (bool, error) {
 	strPhone := string(phone)
-	return r.q.AccountExistsByPhone(ctx, &strPhone)
+	return r.q.AccountExistsByPhone(ctx, sqlc.AccountExistsByPhoneParams{
+		PhoneIndexes: r.blindIndexes(phoneField, strPhone),
+		Phone:        &strPhone,
+	})
+}
+
+func (r *PostgresAccountRepository) UpdateAccountRole(ctx context.Context, accountID valueobject.ID, role valueobject.Role) error {
+	rowsAffected, err := r.q.UpdateAccountRole(ctx, sqlc.UpdateAccountRoleParams{
+		ID:   accountID.ToUUID(),
+		Role: string(role),
+	})
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrAccountNotFound
+	}
+
+	return nil
+}
+
+// EncryptExisting encrypts rows that still hold plaintext identifiers and
+// re-encrypts rows sealed with a key other than the primary one or indexed
+// with a retiring index key. It returns the number of rows updated and is
+// safe to run again after an interruption. Rows whose identifier is already
+// indexed for another account are skipped and logged, to be merged by hand.
+func (r *PostgresAccountRepository) EncryptExisting(ctx context.Context, batchSize int32) (int, error) {
+	if r.cipher == nil {
+		return 0, errors.New("field encryption is not configured")
+	}
+
+	total := 0
+	afterID := uuid.Nil
+	for {
+		accounts, err := r.q.ListAccountsToEncrypt(ctx, sqlc.ListAccountsToEncryptParams{
+			AfterID:      afterID,
+			PrimaryKeyID: r.cipher.PrimaryKeyID(),
+			IndexKeyID:   r.cipher.IndexKeyID(),
+			BatchSize:    batchSize,
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		if len(accounts) == 0 {
+			return total, nil
+		}
+
+		for _, acc := range accounts {
+			afterID = acc.ID
+
+			phone, email, err := r.identifiers(acc)
+			if err != nil {
+				return total, fmt.Errorf("failed to decrypt account %s: %w", acc.ID, err)
+			}
+
+			fields, err := r.encryptIdentifiers(acc.ID, phone, email)
+			if err != nil {
+				return total, err
+			}
+
+			if _, err = r.q.UpdateAccountEncryptedIdentifiers(ctx, fields); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+					logger.Warn("account identifier belongs to another encrypted account, skipping", "account_id", acc.ID)
+					continue
+				}
+				return total, fmt.Errorf("failed to update account %s: %w", acc.ID, err)
+			}
+			total++
+		}
+
+		logger.Info("encrypted account identifiers", "total", total)
+	}
+}
+
+type storedEmail struct {
+	id        uuid.UUID
+	email     string
+	canonical string
+}
+
+// CanonicalizeEmails rewrites plaintext emails into the form canonicalize
+// returns, keeping every replaced address in account_email_originals.
+// Accounts whose canonical email is shared with another account are left
+// alone and reported as duplicates. Emails canonicalize rejects are
+// reported as invalid. With dryRun nothing is written and Canonicalized
+// counts the rows that would change.
+//
+// Every plaintext email is held in memory to find duplicates before anything
+// is written. The run is safe to repeat.
+func (r *PostgresAccountRepository) CanonicalizeEmails(ctx context.Context, canonicalize func(string) (string, error), batchSize int32, dryRun bool) (dto.EmailCanonicalization, error) {
+	var res dto.EmailCanonicalization
+
+	groups := make(map[string][]storedEmail)
+	afterID := uuid.Nil
+	for {
+		rows, err := r.q.ListAccountEmails(ctx, sqlc.ListAccountEmailsParams{
+			AfterID:   afterID,
+			BatchSize: batchSize,
+		})
+		if err != nil {
+			return res, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+
+			canonical, err := canonicalize(*row.Email)
+			if err != nil {
+				res.Invalid = append(res.Invalid, valueobject.ID(row.ID))
+				continue
+			}
+			groups[canonical] = append(groups[canonical], storedEmail{id: row.ID, email: *row.Email, canonical: canonical})
+		}
+	}
+
+	for _, canonical := range slices.Sorted(maps.Keys(groups)) {
+		group := groups[canonical]
+		if len(group) > 1 {
+			res.Duplicates = append(res.Duplicates, duplicateIDs(group))
+			continue
+		}
+
+		stored := group[0]
+		if stored.email == canonical {
+			continue
+		}
+
+		// Encrypted rows are not listed, so one may hold the canonical email.
+		if holder, err := r.GetAccountByEmail(ctx, valueobject.Identifier(canonical)); err == nil {
+			res.Duplicates = append(res.Duplicates, []valueobject.ID{valueobject.ID(stored.id), holder.ID})
+			continue
+		} else if !errors.Is(err, appErrors.ErrAccountNotFound) {
+			return res, fmt.Errorf("failed to look up account %s: %w", stored.id, err)
+		}
+
+		if dryRun {
+			res.Canonicalized++
+			continue
+		}
+
+		updated, err := r.q.CanonicalizeAccountEmail(ctx, sqlc.CanonicalizeAccountEmailParams{
+			ID:        stored.id,
+			Original:  &stored.email,
+			Canonical: &stored.canonical,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+				// Someone signed up with the canonical email meanwhile.
+				res.Duplicates = append(res.Duplicates, []valueobject.ID{valueobject.ID(stored.id)})
+				continue
+			}
+			return res, fmt.Errorf("failed to update account %s: %w", stored.id, err)
+		}
+		if updated > 0 {
+			res.Canonicalized++
+		}
+	}
+
+	return res, nil
+}
+
+func duplicateIDs(group []storedEmail) []valueobject.ID {
+	ids := make([]valueobject.ID, 0, len(group))
+	for _, stored := range group {
+		ids = append(ids, valueobject.ID(stored.id))
+	}
+
+	return ids
 }
 
-func mapSqlcAccount(a sqlc.Account) (*entities.Account, error) {
+func (r *PostgresAccountRepository) encryptIdentifiers(accountID uuid.UUID, phone *string, email *string) (sqlc.UpdateAccountEncryptedIdentifiersParams, error) {
+	keyID, indexKeyID := r.cipher.PrimaryKeyID(), r.cipher.IndexKeyID()
+	res := sqlc.UpdateAccountEncryptedIdentifiersParams{
+		ID:              accountID,
+		EncryptionKeyID: &keyID,
+		IndexKeyID:      &indexKeyID,
+	}
+
+	var err error
+	if phone != nil {
+		res.PhoneEncrypted, err = r.cipher.Encrypt(phoneField, accountID.String(), *phone)
+		if err != nil {
+			return res, fmt.Errorf("failed to encrypt phone: %w", err)
+		}
+		res.PhoneIndex = r.cipher.BlindIndex(phoneField, *phone)
+	}
+	if email != nil {
+		res.EmailEncrypted, err = r.cipher.Encrypt(emailField, accountID.String(), *email)
+		if err != nil {
+			return res, fmt.Errorf("failed to encrypt email: %w", err)
+		}
+		res.EmailIndex = r.cipher.BlindIndex(emailField, *email)
+	}
+
+	return res, nil
+}
+
+// identifiers prefers the encrypted columns and falls back to the plaintext
+// ones for rows that have not been encrypted yet.
+func (r *PostgresAccountRepository) identifiers(a sqlc.Account) (*string, *string, error) {
+	phone, email := a.Phone, a.Email
+	if a.PhoneEncrypted == nil && a.EmailEncrypted == nil {
+		return phone, email, nil
+	}
+
+	if r.cipher == nil {
+		return nil, nil, errors.New("account identifiers are encrypted but field encryption is not configured")
+	}
+
+	if a.PhoneEncrypted != nil {
+		value, err := r.cipher.Decrypt(phoneField, a.ID.String(), a.PhoneEncrypted)
+		if err != nil {
+			return nil, nil, err
+		}
+		phone = &value
+	}
+	if a.EmailEncrypted != nil {
+		value, err := r.cipher.Decrypt(emailField, a.ID.String(), a.EmailEncrypted)
+		if err != nil {
+			return nil, nil, err
+		}
+		email = &value
+	}
+
+	return phone, email, nil
+}
+
+func (r *PostgresAccountRepository) blindIndexes(field string, value string) [][]byte {
+	if r.cipher == nil {
+		return nil
+	}
+
+	return r.cipher.BlindIndexes(field, value)
+}
+
+// lockKey maps an identifier to an advisory lock key. Only a hash of the
+// identifier reaches the database.
+func lockKey(field string, value string) int64 {
+	sum := sha256.Sum256([]byte(field + ":" + value))
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+func (r *PostgresAccountRepository) mapSqlcAccount(a sqlc.Account) (*entities.Account, error) {
 	role := valueobject.Role(a.Role)
 	err := role.Validate()
 	if err != nil {
 		return nil, err
 	}
 
+	phone, email, err := r.identifiers(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt account identifiers: %w", err)
+	}
+
 	return &entities.Account{
 		ID:        valueobject.ID(a.ID),
-		Email:     a.Email,
-		Phone:     a.Phone,
+		Email:     email,
+		Phone:     phone,
 		Role:      role,
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
@@ -0,0 +1,125 @@
+package account
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+// fakeQuerier keeps accounts in memory. Calling a query it does not
+// implement panics through the nil embedded interface.
+type fakeQuerier struct {
+	sqlc.Querier
+	accounts  []sqlc.Account
+	originals map[uuid.UUID]string
+}
+
+func (q *fakeQuerier) ListAccountEmails(_ context.Context, arg sqlc.ListAccountEmailsParams) ([]sqlc.ListAccountEmailsRow, error) {
+	rows := []sqlc.ListAccountEmailsRow{}
+	for _, acc := range q.accounts {
+		if acc.Email != nil && strings.Compare(acc.ID.String(), arg.AfterID.String()) > 0 && len(rows) < int(arg.BatchSize) {
+			rows = append(rows, sqlc.ListAccountEmailsRow{ID: acc.ID, Email: acc.Email})
+		}
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetAccountByEmail(_ context.Context, arg sqlc.GetAccountByEmailParams) (sqlc.Account, error) {
+	for _, acc := range q.accounts {
+		if acc.Email != nil && *acc.Email == *arg.Email {
+			return acc, nil
+		}
+	}
+	return sqlc.Account{}, pgx.ErrNoRows
+}
+
+func (q *fakeQuerier) CanonicalizeAccountEmail(_ context.Context, arg sqlc.CanonicalizeAccountEmailParams) (int64, error) {
+	for i, acc := range q.accounts {
+		if acc.ID == arg.ID && acc.Email != nil && *acc.Email == *arg.Original {
+			q.originals[acc.ID] = *acc.Email
+			q.accounts[i].Email = arg.Canonical
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func newFakeQuerier(emails ...string) *fakeQuerier {
+	q := &fakeQuerier{originals: make(map[uuid.UUID]string)}
+	for _, email := range emails {
+		q.accounts = append(q.accounts, sqlc.Account{ID: uuid.New(), Email: &email, Role: "user"})
+	}
+	slices.SortFunc(q.accounts, func(a, b sqlc.Account) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return q
+}
+
+func (q *fakeQuerier) idOf(email string) uuid.UUID {
+	for _, acc := range q.accounts {
+		if acc.Email != nil && *acc.Email == email {
+			return acc.ID
+		}
+	}
+	return uuid.Nil
+}
+
+func lowercase(email string) (string, error) {
+	return strings.ToLower(email), nil
+}
+
+func TestCanonicalizeEmails(t *testing.T) {
+	q := newFakeQuerier("Alice@Example.com", "bob@example.com", "Carol@example.com", "carol@Example.com")
+	aliceID, carolIDs := q.idOf("Alice@Example.com"), []uuid.UUID{q.idOf("Carol@example.com"), q.idOf("carol@Example.com")}
+	repo := NewPostgresAccountRepository(q, nil)
+
+	res, err := repo.CanonicalizeEmails(context.Background(), lowercase, 2, false)
+	if err != nil {
+		t.Fatalf("CanonicalizeEmails() error = %v", err)
+	}
+
+	if res.Canonicalized != 1 {
+		t.Errorf("Canonicalized = %d, want 1", res.Canonicalized)
+	}
+	if got := q.originals[aliceID]; got != "Alice@Example.com" {
+		t.Errorf("original of alice = %q, want %q", got, "Alice@Example.com")
+	}
+	if q.idOf("alice@example.com") != aliceID {
+		t.Error("alice's email was not canonicalized")
+	}
+
+	if len(res.Duplicates) != 1 || len(res.Duplicates[0]) != 2 {
+		t.Fatalf("Duplicates = %v, want one group of two", res.Duplicates)
+	}
+	for _, id := range carolIDs {
+		if !slices.Contains(res.Duplicates[0], valueobject.ID(id)) {
+			t.Errorf("duplicate group is missing %s", id)
+		}
+		if _, changed := q.originals[id]; changed {
+			t.Errorf("duplicate account %s was rewritten", id)
+		}
+	}
+}
+
+func TestCanonicalizeEmailsDryRun(t *testing.T) {
+	q := newFakeQuerier("Alice@Example.com")
+	repo := NewPostgresAccountRepository(q, nil)
+
+	res, err := repo.CanonicalizeEmails(context.Background(), lowercase, 10, true)
+	if err != nil {
+		t.Fatalf("CanonicalizeEmails() error = %v", err)
+	}
+
+	if res.Canonicalized != 1 {
+		t.Errorf("Canonicalized = %d, want 1", res.Canonicalized)
+	}
+	if len(q.originals) != 0 || q.idOf("Alice@Example.com") == uuid.Nil {
+		t.Error("dry run wrote to the database")
+	}
+}
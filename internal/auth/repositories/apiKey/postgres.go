@@ -0,0 +1,85 @@
+package apiKey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+type PostgresApiKeyRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresApiKeyRepository(q sqlc.Querier) *PostgresApiKeyRepository {
+	return &PostgresApiKeyRepository{q: q}
+}
+
+func (r *PostgresApiKeyRepository) CreateApiKey(ctx context.Context, arg dto.CreateApiKeyParams) error {
+	return r.q.CreateApiKey(ctx, sqlc.CreateApiKeyParams{
+		ID:        arg.ID.ToUUID(),
+		AccountID: arg.AccountID.ToUUID(),
+		Name:      arg.Name,
+		Prefix:    arg.Prefix,
+		KeyHash:   arg.KeyHash,
+		Scopes:    arg.Scopes,
+		ExpiresAt: arg.ExpiresAt,
+	})
+}
+
+func (r *PostgresApiKeyRepository) GetActiveApiKeyByHash(ctx context.Context, keyHash string) (*entities.ApiKey, error) {
+	key, err := r.q.GetActiveApiKeyByHash(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, appErrors.ErrApiKeyNotFound
+		}
+		return nil, err
+	}
+
+	return mapSqlcApiKey(key), nil
+}
+
+func (r *PostgresApiKeyRepository) ListApiKeysByAccountID(ctx context.Context, accountID valueobject.ID) ([]*entities.ApiKey, error) {
+	keys, err := r.q.ListApiKeysByAccountID(ctx, accountID.ToUUID())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entities.ApiKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, mapSqlcApiKey(key))
+	}
+
+	return res, nil
+}
+
+func (r *PostgresApiKeyRepository) RevokeApiKey(ctx context.Context, accountID valueobject.ID, keyID valueobject.ID) (int64, error) {
+	return r.q.RevokeApiKey(ctx, sqlc.RevokeApiKeyParams{
+		ID:        keyID.ToUUID(),
+		AccountID: accountID.ToUUID(),
+	})
+}
+
+func (r *PostgresApiKeyRepository) UpdateApiKeyLastUsedAt(ctx context.Context, keyID valueobject.ID) error {
+	return r.q.UpdateApiKeyLastUsedAt(ctx, keyID.ToUUID())
+}
+
+func mapSqlcApiKey(k sqlc.ApiKey) *entities.ApiKey {
+	return &entities.ApiKey{
+		ID:         valueobject.ID(k.ID),
+		AccountID:  valueobject.ID(k.AccountID),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		KeyHash:    k.KeyHash,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
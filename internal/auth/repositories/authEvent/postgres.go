@@ -0,0 +1,100 @@
+package authEvent
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+type PostgresAuthEventRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresAuthEventRepository(q sqlc.Querier) *PostgresAuthEventRepository {
+	return &PostgresAuthEventRepository{q: q}
+}
+
+func (r *PostgresAuthEventRepository) CreateAuthEvent(ctx context.Context, arg dto.CreateAuthEventParams) error {
+	return r.q.CreateAuthEvent(ctx, sqlc.CreateAuthEventParams{
+		ID:             arg.ID.ToUUID(),
+		EventType:      string(arg.Type),
+		AccountID:      toNullableUUID(arg.AccountID),
+		IdentifierHash: toNullableString(arg.IdentifierHash),
+		Ip:             toNullableString(arg.IP),
+		UserAgent:      toNullableString(arg.UserAgent),
+		ClientID:       toNullableString(arg.ClientID),
+		Outcome:        string(arg.Outcome),
+		ErrorCode:      toNullableString(arg.ErrorCode),
+	})
+}
+
+func (r *PostgresAuthEventRepository) ListAuthEvents(ctx context.Context, arg dto.ListAuthEventsParams) ([]*entities.AuthEvent, error) {
+	events, err := r.q.ListAuthEvents(ctx, sqlc.ListAuthEventsParams{
+		AccountID:       toNullableUUID(arg.AccountID),
+		FromTime:        arg.From,
+		ToTime:          arg.To,
+		CursorCreatedAt: arg.CursorCreatedAt,
+		CursorID:        toNullableUUID(arg.CursorID),
+		PageSize:        arg.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entities.AuthEvent, 0, len(events))
+	for _, event := range events {
+		res = append(res, mapSqlcAuthEvent(event))
+	}
+
+	return res, nil
+}
+
+func (r *PostgresAuthEventRepository) DeleteAuthEventsBefore(ctx context.Context, before time.Time, batchSize int32) (int64, error) {
+	return r.q.DeleteAuthEventsBefore(ctx, sqlc.DeleteAuthEventsBeforeParams{
+		Before:    before,
+		BatchSize: batchSize,
+	})
+}
+
+func mapSqlcAuthEvent(e sqlc.AuthEvent) *entities.AuthEvent {
+	var accountID *valueobject.ID
+	if e.AccountID != nil {
+		id := valueobject.ID(*e.AccountID)
+		accountID = &id
+	}
+
+	return &entities.AuthEvent{
+		ID:             valueobject.ID(e.ID),
+		Type:           valueobject.AuthEventType(e.EventType),
+		AccountID:      accountID,
+		IdentifierHash: e.IdentifierHash,
+		IP:             e.Ip,
+		UserAgent:      e.UserAgent,
+		ClientID:       e.ClientID,
+		Outcome:        valueobject.AuthEventOutcome(e.Outcome),
+		ErrorCode:      e.ErrorCode,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+func toNullableUUID(id *valueobject.ID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+
+	u := id.ToUUID()
+	return &u
+}
+
+func toNullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
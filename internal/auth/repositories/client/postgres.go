@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+type PostgresClientRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresClientRepository(q sqlc.Querier) *PostgresClientRepository {
+	return &PostgresClientRepository{q: q}
+}
+
+func (r *PostgresClientRepository) GetClientByID(ctx context.Context, clientID string) (*entities.Client, error) {
+	c, err := r.q.GetClientByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, appErrors.ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return mapSqlcClient(c)
+}
+
+// GetLongestAccessTokenTTL returns 0 when no client sets its own TTL.
+func (r *PostgresClientRepository) GetLongestAccessTokenTTL(ctx context.Context) (time.Duration, error) {
+	seconds, err := r.q.GetLongestAccessTokenTTL(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func mapSqlcClient(c sqlc.Client) (*entities.Client, error) {
+	clientType := valueobject.ClientType(c.Type)
+	if err := clientType.Validate(); err != nil {
+		return nil, err
+	}
+
+	grantTypes := make([]valueobject.GrantType, 0, len(c.GrantTypes))
+	for _, g := range c.GrantTypes {
+		grantType := valueobject.GrantType(g)
+		if err := grantType.Validate(); err != nil {
+			return nil, err
+		}
+		grantTypes = append(grantTypes, grantType)
+	}
+
+	return &entities.Client{
+		ID:              c.ID,
+		Name:            c.Name,
+		Type:            clientType,
+		GrantTypes:      grantTypes,
+		Scopes:          c.Scopes,
+		AccessTokenTTL:  secondsToDuration(c.AccessTokenTtlSeconds),
+		RefreshTokenTTL: secondsToDuration(c.RefreshTokenTtlSeconds),
+		SecretHash:      c.SecretHash,
+		PublicKey:       c.PublicKey,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+	}, nil
+}
+
+func secondsToDuration(seconds *int32) *time.Duration {
+	if seconds == nil {
+		return nil
+	}
+	d := time.Duration(*seconds) * time.Second
+	return &d
+}
@@ -0,0 +1,32 @@
+package impersonation
+
+import (
+	"context"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+type PostgresImpersonationRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresImpersonationRepository(q sqlc.Querier) *PostgresImpersonationRepository {
+	return &PostgresImpersonationRepository{q: q}
+}
+
+func (r *PostgresImpersonationRepository) CreateImpersonation(ctx context.Context, arg dto.CreateImpersonationParams) error {
+	var clientID *string
+	if arg.ClientID != "" {
+		clientID = &arg.ClientID
+	}
+
+	return r.q.CreateImpersonation(ctx, sqlc.CreateImpersonationParams{
+		ID:              arg.ID.ToUUID(),
+		ActorAccountID:  arg.ActorAccountID.ToUUID(),
+		TargetAccountID: arg.TargetAccountID.ToUUID(),
+		ClientID:        clientID,
+		Reason:          arg.Reason,
+		ExpiresAt:       arg.ExpiresAt,
+	})
+}
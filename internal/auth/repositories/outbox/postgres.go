@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+type PostgresOutboxRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresOutboxRepository(q sqlc.Querier) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{q: q}
+}
+
+func (r *PostgresOutboxRepository) CreateOutboxEvent(ctx context.Context, arg dto.CreateOutboxEventParams) error {
+	return r.q.CreateOutboxEvent(ctx, sqlc.CreateOutboxEventParams{
+		ID:            arg.ID.ToUUID(),
+		AggregateType: arg.AggregateType,
+		AggregateID:   arg.AggregateID.ToUUID(),
+		EventType:     arg.EventType,
+		Payload:       arg.Payload,
+	})
+}
+
+func (r *PostgresOutboxRepository) ClaimOutboxEvents(ctx context.Context, lockedUntil time.Time, batchSize int32) ([]*entities.OutboxEvent, error) {
+	events, err := r.q.ClaimOutboxEvents(ctx, sqlc.ClaimOutboxEventsParams{
+		LockedUntil: lockedUntil,
+		BatchSize:   batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entities.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		res = append(res, mapSqlcOutboxEvent(event))
+	}
+
+	return res, nil
+}
+
+func (r *PostgresOutboxRepository) DeleteOutboxEvent(ctx context.Context, eventID valueobject.ID) error {
+	return r.q.DeleteOutboxEvent(ctx, eventID.ToUUID())
+}
+
+func (r *PostgresOutboxRepository) MarkOutboxEventFailed(ctx context.Context, arg dto.OutboxEventFailure) error {
+	return r.q.MarkOutboxEventFailed(ctx, sqlc.MarkOutboxEventFailedParams{
+		ID:            arg.ID.ToUUID(),
+		NextAttemptAt: arg.NextAttemptAt,
+		LastError:     &arg.LastError,
+	})
+}
+
+func mapSqlcOutboxEvent(e sqlc.Outbox) *entities.OutboxEvent {
+	return &entities.OutboxEvent{
+		ID:            valueobject.ID(e.ID),
+		AggregateType: e.AggregateType,
+		AggregateID:   valueobject.ID(e.AggregateID),
+		EventType:     e.EventType,
+		Payload:       e.Payload,
+		Attempts:      e.Attempts,
+		CreatedAt:     e.CreatedAt,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
 	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
 )
@@ -24,6 +25,7 @@ func (r *PostgresRefreshTokenRepository) CreateRefreshToken(ctx context.Context,
 	param := sqlc.CreateRefreshTokenParams{
 		ID:        arg.ID,
 		AccountID: arg.AccountID,
+		ClientID:  &arg.ClientID,
 		TokenHash: arg.TokenHash,
 		ExpiresAt: arg.ExpiresAt,
 	}
@@ -46,15 +48,24 @@ func (r *PostgresRefreshTokenRepository) DeleteRefreshTokenByHash(ctx context.Co
 	return r.q.DeleteRefreshTokenByHash(ctx, tokenHash)
 }
 
+func (r *PostgresRefreshTokenRepository) DeleteRefreshTokenByHashAndClientID(ctx context.Context, tokenHash string, clientID string) (int64, error) {
+	return r.q.DeleteRefreshTokenByHashAndClientID(ctx, sqlc.DeleteRefreshTokenByHashAndClientIDParams{
+		TokenHash: tokenHash,
+		ClientID:  &clientID,
+	})
+}
+
 func (r *PostgresRefreshTokenRepository) DeleteRefreshTokensByAccountID(ctx context.Context, accountID uuid.UUID) error {
 	return r.q.DeleteRefreshTokensByAccountID(ctx, accountID)
 }
 
 func mapSqlcRefreshToken(a sqlc.RefreshToken) *entities.RefreshToken {
 	return &entities.RefreshToken{
-		ID:        a.ID,
+		ID:        valueobject.ID(a.ID),
 		AccountID: a.AccountID,
+		ClientID:  a.ClientID,
 		TokenHash: a.TokenHash,
 		ExpiresAt: a.ExpiresAt,
+		CreatedAt: a.CreatedAt,
 	}
 }
@@ -0,0 +1,149 @@
+package role
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+)
+
+const foreignKeyViolationCode = "23503"
+
+type PostgresRoleRepository struct {
+	q sqlc.Querier
+}
+
+func NewPostgresRoleRepository(q sqlc.Querier) *PostgresRoleRepository {
+	return &PostgresRoleRepository{q: q}
+}
+
+func (r *PostgresRoleRepository) ListRoles(ctx context.Context) ([]*entities.Role, error) {
+	roles, err := r.q.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rolePermissions, err := r.q.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsByRole := make(map[string][]valueobject.Permission, len(roles))
+	for _, rp := range rolePermissions {
+		permissionsByRole[rp.Role] = append(permissionsByRole[rp.Role], valueobject.Permission(rp.Permission))
+	}
+
+	res := make([]*entities.Role, 0, len(roles))
+	for _, role := range roles {
+		permissions := permissionsByRole[role.Name]
+		if permissions == nil {
+			permissions = []valueobject.Permission{}
+		}
+		res = append(res, &entities.Role{
+			Name:        valueobject.Role(role.Name),
+			Description: role.Description,
+			Permissions: permissions,
+			CreatedAt:   role.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+func (r *PostgresRoleRepository) RoleExists(ctx context.Context, role valueobject.Role) (bool, error) {
+	return r.q.RoleExists(ctx, string(role))
+}
+
+func (r *PostgresRoleRepository) CreateRole(ctx context.Context, role valueobject.Role, description string) error {
+	rowsAffected, err := r.q.CreateRole(ctx, sqlc.CreateRoleParams{
+		Name:        string(role),
+		Description: description,
+	})
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrRoleAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *PostgresRoleRepository) DeleteRole(ctx context.Context, role valueobject.Role) error {
+	rowsAffected, err := r.q.DeleteRole(ctx, string(role))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+			return appErrors.ErrRoleInUse
+		}
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRoleRepository) ListPermissions(ctx context.Context) ([]*entities.Permission, error) {
+	permissions, err := r.q.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entities.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		res = append(res, &entities.Permission{
+			Name:        valueobject.Permission(p.Name),
+			Description: p.Description,
+		})
+	}
+
+	return res, nil
+}
+
+func (r *PostgresRoleRepository) ListPermissionsByRole(ctx context.Context, role valueobject.Role) ([]valueobject.Permission, error) {
+	permissions, err := r.q.ListPermissionsByRole(ctx, string(role))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]valueobject.Permission, 0, len(permissions))
+	for _, p := range permissions {
+		res = append(res, valueobject.Permission(p))
+	}
+
+	return res, nil
+}
+
+func (r *PostgresRoleRepository) CountAccountsWithPermission(ctx context.Context, permission valueobject.Permission) (int64, error) {
+	return r.q.CountAccountsWithPermission(ctx, string(permission))
+}
+
+func (r *PostgresRoleRepository) SetRolePermissions(ctx context.Context, role valueobject.Role, permissions []valueobject.Permission) error {
+	if err := r.q.DeleteRolePermissions(ctx, string(role)); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		err := r.q.AddRolePermission(ctx, sqlc.AddRolePermissionParams{
+			Role:       string(role),
+			Permission: string(permission),
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolationCode {
+				return appErrors.ErrInvalidPermission
+			}
+			return err
+		}
+	}
+
+	return nil
+}
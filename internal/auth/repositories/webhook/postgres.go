@@ -0,0 +1,329 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/fieldcrypt"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+	"github.com/teacinema-go/core/logger"
+)
+
+const secretField = "webhook_secret"
+
+// PostgresWebhookRepository stores signing secrets encrypted when it has a
+// cipher. Subscriptions created before encryption was enabled keep their
+// plaintext secret until EncryptExisting moves it.
+type PostgresWebhookRepository struct {
+	q      sqlc.Querier
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPostgresWebhookRepository accepts a nil cipher to store secrets in
+// plaintext.
+func NewPostgresWebhookRepository(q sqlc.Querier, cipher *fieldcrypt.Cipher) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{
+		q:      q,
+		cipher: cipher,
+	}
+}
+
+func (r *PostgresWebhookRepository) CreateWebhookSubscription(ctx context.Context, arg dto.CreateWebhookSubscriptionParams) error {
+	param := sqlc.CreateWebhookSubscriptionParams{
+		ID:         arg.ID.ToUUID(),
+		Url:        arg.URL,
+		EventTypes: arg.EventTypes,
+		Secret:     &arg.Secret,
+	}
+
+	if r.cipher != nil {
+		encrypted, err := r.cipher.Encrypt(secretField, param.ID.String(), arg.Secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt webhook secret: %w", err)
+		}
+
+		keyID := r.cipher.PrimaryKeyID()
+		param.Secret = nil
+		param.SecretEncrypted = encrypted
+		param.EncryptionKeyID = &keyID
+	}
+
+	return r.q.CreateWebhookSubscription(ctx, param)
+}
+
+func (r *PostgresWebhookRepository) GetWebhookSubscriptionByID(ctx context.Context, subscriptionID valueobject.ID) (*entities.WebhookSubscription, error) {
+	subscription, err := r.q.GetWebhookSubscriptionByID(ctx, subscriptionID.ToUUID())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, appErrors.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return r.mapSqlcWebhookSubscription(subscription)
+}
+
+func (r *PostgresWebhookRepository) ListWebhookSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	subscriptions, err := r.q.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapSqlcWebhookSubscriptions(subscriptions)
+}
+
+func (r *PostgresWebhookRepository) ListWebhookSubscriptionsForEvent(ctx context.Context, eventType string) ([]*entities.WebhookSubscription, error) {
+	subscriptions, err := r.q.ListWebhookSubscriptionsForEvent(ctx, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.mapSqlcWebhookSubscriptions(subscriptions)
+}
+
+func (r *PostgresWebhookRepository) DeleteWebhookSubscription(ctx context.Context, subscriptionID valueobject.ID) error {
+	rowsAffected, err := r.q.DeleteWebhookSubscription(ctx, subscriptionID.ToUUID())
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) CreateWebhookDelivery(ctx context.Context, arg dto.CreateWebhookDeliveryParams) error {
+	return r.q.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+		ID:             arg.ID.ToUUID(),
+		SubscriptionID: arg.SubscriptionID.ToUUID(),
+		EventID:        arg.EventID.ToUUID(),
+		EventType:      arg.EventType,
+		Payload:        arg.Payload,
+	})
+}
+
+func (r *PostgresWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, lockedUntil time.Time, batchSize int32) ([]*entities.WebhookDelivery, error) {
+	deliveries, err := r.q.ClaimWebhookDeliveries(ctx, sqlc.ClaimWebhookDeliveriesParams{
+		LockedUntil: lockedUntil,
+		BatchSize:   batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapSqlcWebhookDeliveries(deliveries), nil
+}
+
+func (r *PostgresWebhookRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID valueobject.ID, status *valueobject.WebhookDeliveryStatus, limit int32) ([]*entities.WebhookDelivery, error) {
+	var statusFilter *string
+	if status != nil {
+		s := string(*status)
+		statusFilter = &s
+	}
+
+	deliveries, err := r.q.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: subscriptionID.ToUUID(),
+		Status:         statusFilter,
+		PageSize:       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapSqlcWebhookDeliveries(deliveries), nil
+}
+
+func (r *PostgresWebhookRepository) MarkWebhookDeliverySucceeded(ctx context.Context, deliveryID valueobject.ID, statusCode int32) error {
+	return r.q.MarkWebhookDeliverySucceeded(ctx, sqlc.MarkWebhookDeliverySucceededParams{
+		ID:             deliveryID.ToUUID(),
+		LastStatusCode: &statusCode,
+	})
+}
+
+func (r *PostgresWebhookRepository) MarkWebhookDeliveryFailed(ctx context.Context, arg dto.WebhookDeliveryFailure) error {
+	return r.q.MarkWebhookDeliveryFailed(ctx, sqlc.MarkWebhookDeliveryFailedParams{
+		ID:             arg.ID.ToUUID(),
+		Status:         string(arg.Status),
+		NextAttemptAt:  arg.NextAttemptAt,
+		LastStatusCode: arg.StatusCode,
+		LastError:      &arg.Error,
+	})
+}
+
+func (r *PostgresWebhookRepository) ReplayWebhookDelivery(ctx context.Context, deliveryID valueobject.ID) error {
+	rowsAffected, err := r.q.ReplayWebhookDelivery(ctx, deliveryID.ToUUID())
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresWebhookRepository) CreateWebhookDeliveryAttempt(ctx context.Context, arg dto.CreateWebhookDeliveryAttemptParams) error {
+	var attemptError *string
+	if arg.Error != "" {
+		attemptError = &arg.Error
+	}
+
+	return r.q.CreateWebhookDeliveryAttempt(ctx, sqlc.CreateWebhookDeliveryAttemptParams{
+		ID:         arg.ID.ToUUID(),
+		DeliveryID: arg.DeliveryID.ToUUID(),
+		StatusCode: arg.StatusCode,
+		Error:      attemptError,
+		DurationMs: int32(arg.Duration.Milliseconds()),
+	})
+}
+
+func (r *PostgresWebhookRepository) ListWebhookDeliveryAttempts(ctx context.Context, deliveryID valueobject.ID) ([]*entities.WebhookDeliveryAttempt, error) {
+	attempts, err := r.q.ListWebhookDeliveryAttempts(ctx, deliveryID.ToUUID())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entities.WebhookDeliveryAttempt, 0, len(attempts))
+	for _, a := range attempts {
+		res = append(res, &entities.WebhookDeliveryAttempt{
+			ID:         valueobject.ID(a.ID),
+			DeliveryID: valueobject.ID(a.DeliveryID),
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			Duration:   time.Duration(a.DurationMs) * time.Millisecond,
+			CreatedAt:  a.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
+
+// EncryptExisting encrypts plaintext secrets and re-encrypts secrets sealed
+// with a key other than the primary one. It returns the number of
+// subscriptions updated and is safe to run again after an interruption.
+func (r *PostgresWebhookRepository) EncryptExisting(ctx context.Context, batchSize int32) (int, error) {
+	if r.cipher == nil {
+		return 0, errors.New("field encryption is not configured")
+	}
+
+	total := 0
+	afterID := uuid.Nil
+	for {
+		subscriptions, err := r.q.ListWebhookSubscriptionsToEncrypt(ctx, sqlc.ListWebhookSubscriptionsToEncryptParams{
+			AfterID:      afterID,
+			PrimaryKeyID: r.cipher.PrimaryKeyID(),
+			BatchSize:    batchSize,
+		})
+		if err != nil {
+			return total, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+		}
+		if len(subscriptions) == 0 {
+			return total, nil
+		}
+
+		for _, subscription := range subscriptions {
+			afterID = subscription.ID
+
+			secret, err := r.secret(subscription)
+			if err != nil {
+				return total, fmt.Errorf("failed to decrypt webhook subscription %s: %w", subscription.ID, err)
+			}
+
+			encrypted, err := r.cipher.Encrypt(secretField, subscription.ID.String(), secret)
+			if err != nil {
+				return total, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+			}
+
+			keyID := r.cipher.PrimaryKeyID()
+			_, err = r.q.UpdateWebhookSubscriptionEncryptedSecret(ctx, sqlc.UpdateWebhookSubscriptionEncryptedSecretParams{
+				ID:              subscription.ID,
+				SecretEncrypted: encrypted,
+				EncryptionKeyID: &keyID,
+			})
+			if err != nil {
+				return total, fmt.Errorf("failed to update webhook subscription %s: %w", subscription.ID, err)
+			}
+			total++
+		}
+
+		logger.Info("encrypted webhook secrets", "total", total)
+	}
+}
+
+// secret prefers the encrypted column and falls back to the plaintext one for
+// subscriptions that have not been encrypted yet.
+func (r *PostgresWebhookRepository) secret(s sqlc.WebhookSubscription) (string, error) {
+	if s.SecretEncrypted == nil {
+		if s.Secret == nil {
+			return "", errors.New("webhook subscription has no secret")
+		}
+		return *s.Secret, nil
+	}
+
+	if r.cipher == nil {
+		return "", errors.New("webhook secret is encrypted but field encryption is not configured")
+	}
+
+	return r.cipher.Decrypt(secretField, s.ID.String(), s.SecretEncrypted)
+}
+
+func (r *PostgresWebhookRepository) mapSqlcWebhookSubscription(s sqlc.WebhookSubscription) (*entities.WebhookSubscription, error) {
+	secret, err := r.secret(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret of webhook subscription %s: %w", s.ID, err)
+	}
+
+	return &entities.WebhookSubscription{
+		ID:         valueobject.ID(s.ID),
+		URL:        s.Url,
+		EventTypes: s.EventTypes,
+		Secret:     secret,
+		CreatedAt:  s.CreatedAt,
+	}, nil
+}
+
+func (r *PostgresWebhookRepository) mapSqlcWebhookSubscriptions(subscriptions []sqlc.WebhookSubscription) ([]*entities.WebhookSubscription, error) {
+	res := make([]*entities.WebhookSubscription, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		subscription, err := r.mapSqlcWebhookSubscription(s)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, subscription)
+	}
+
+	return res, nil
+}
+
+func mapSqlcWebhookDeliveries(deliveries []sqlc.WebhookDelivery) []*entities.WebhookDelivery {
+	res := make([]*entities.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		res = append(res, &entities.WebhookDelivery{
+			ID:             valueobject.ID(d.ID),
+			SubscriptionID: valueobject.ID(d.SubscriptionID),
+			EventID:        valueobject.ID(d.EventID),
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Status:         valueobject.WebhookDeliveryStatus(d.Status),
+			Attempts:       d.Attempts,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastStatusCode: d.LastStatusCode,
+			LastError:      d.LastError,
+			DeliveredAt:    d.DeliveredAt,
+			CreatedAt:      d.CreatedAt,
+		})
+	}
+
+	return res
+}
@@ -6,17 +6,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
 	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
-	"github.com/teacinema-go/auth-service/pkg/utils"
-	"github.com/teacinema-go/passport"
-)
-
-const (
-	accessTokenTTL  = 40 * time.Minute
-	refreshTokenTTL = 14 * 24 * time.Hour
 )
 
 func (s *AuthService) AccountExists(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (bool, error) {
@@ -27,13 +22,14 @@ func (s *AuthService) AccountExists(ctx context.Context, identifier valueobject.
 	return s.accountRepo.AccountExistsByEmail(ctx, identifier)
 }
 
-func (s *AuthService) CreateAccountWithTokens(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (dto.Tokens, error) {
-	res, err := s.txManager.WithTransaction(ctx, func(repos TxRepositories) (any, error) {
+func (s *AuthService) CreateAccountWithTokens(ctx context.Context, client *entities.Client, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (dto.Tokens, error) {
+	accountID, err := valueobject.NewID()
+	if err != nil {
+		return dto.Tokens{}, err
+	}
+
+	tokens, err := WithTx(ctx, s.txManager, TxOptions{}, func(ctx context.Context) (dto.Tokens, error) {
 		// Create an account
-		accountID, err := valueobject.NewID()
-		if err != nil {
-			return nil, err
-		}
 		params := dto.CreateAccountParams{
 			ID:   accountID,
 			Role: valueobject.RoleUser,
@@ -44,43 +40,35 @@ func (s *AuthService) CreateAccountWithTokens(ctx context.Context, identifier va
 		} else {
 			params.Email = &strIdentifier
 		}
-		err = s.accountRepo.CreateAccount(ctx, params)
+		err := s.accountRepo.CreateAccount(ctx, params)
 		if err != nil {
 			if errors.Is(err, appErrors.ErrAccountAlreadyExists) {
-				return nil, appErrors.ErrAccountAlreadyExists
+				return dto.Tokens{}, appErrors.ErrAccountAlreadyExists
 			}
-			return nil, fmt.Errorf("failed to create account: %w", err)
-		}
-
-		// Generate tokens
-		tokenID, err := valueobject.NewID()
-		if err != nil {
-			return nil, err
+			return dto.Tokens{}, fmt.Errorf("failed to create account: %w", err)
 		}
 
-		refreshToken := passport.GenerateToken(s.secretKey, accountID.ToUUID().String(), refreshTokenTTL)
-		err = repos.RefreshToken().CreateRefreshToken(ctx, dto.CreateRefreshTokenParams{
-			ID:        tokenID.ToUUID(),
-			AccountID: accountID.ToUUID(),
-			TokenHash: utils.GenerateHash(refreshToken.Val),
-			ExpiresAt: time.Unix(refreshToken.Exp, 0),
+		err = s.enqueueAccountEvent(ctx, accountID, events.TypeAccountCreated, events.AccountCreated{
+			AccountID:      accountID.ToString(),
+			IdentifierType: identifierType,
+			Role:           params.Role,
+			ClientID:       client.ID,
+			OccurredAt:     time.Now(),
 		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create refresh token: %w", err)
+			return dto.Tokens{}, err
 		}
-		accessToken := passport.GenerateToken(s.secretKey, accountID.ToUUID().String(), accessTokenTTL)
 
-		return dto.Tokens{
-			AccessToken:  accessToken.Val,
-			RefreshToken: refreshToken.Val,
-			ExpiresIn:    int32(accessTokenTTL.Seconds()),
-		}, nil
+		return s.issueTokens(ctx, client, accountID, params.Role, uuid.Nil)
 	})
 	if err != nil {
 		return dto.Tokens{}, err
 	}
 
-	return res.(dto.Tokens), nil
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeAccountCreated, accountID, client.ID)
+	s.metrics.TokenIssued(valueobject.GrantTypeOtp)
+
+	return tokens, nil
 }
 
 func (s *AuthService) GetAccount(ctx context.Context, accountID valueobject.ID) (*entities.Account, error) {
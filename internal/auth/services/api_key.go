@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+)
+
+const (
+	apiKeyPrefix        = "tck_"
+	apiKeyPrefixBytes   = 4
+	apiKeySecretBytes   = 24
+	apiKeyMaxNameLength = 100
+)
+
+func IsApiKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyPrefix)
+}
+
+func (s *AuthService) CreateApiKey(ctx context.Context, accountID valueobject.ID, name string, scopes []string, expiresAt *time.Time) (dto.CreatedApiKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiKeyMaxNameLength {
+		return dto.CreatedApiKey{}, appErrors.ErrInvalidApiKeyName
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return dto.CreatedApiKey{}, appErrors.ErrInvalidApiKeyExpiry
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return dto.CreatedApiKey{}, err
+	}
+
+	permissions, err := s.roleRepo.ListPermissionsByRole(ctx, account.Role)
+	if err != nil {
+		return dto.CreatedApiKey{}, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+
+	// Scopes must name permissions the account's role actually grants.
+	for _, scope := range scopes {
+		permission := valueobject.Permission(scope)
+		if permission.Validate() != nil || !slices.Contains(permissions, permission) {
+			return dto.CreatedApiKey{}, appErrors.ErrInvalidScope
+		}
+	}
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+
+	keyID, err := valueobject.NewID()
+	if err != nil {
+		return dto.CreatedApiKey{}, err
+	}
+
+	publicPart, err := utils.GenerateRandomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return dto.CreatedApiKey{}, fmt.Errorf("failed to generate api key prefix: %w", err)
+	}
+	secretPart, err := utils.GenerateRandomHex(apiKeySecretBytes)
+	if err != nil {
+		return dto.CreatedApiKey{}, fmt.Errorf("failed to generate api key secret: %w", err)
+	}
+
+	prefix := apiKeyPrefix + publicPart
+	key := prefix + "_" + secretPart
+
+	params := dto.CreateApiKeyParams{
+		ID:        keyID,
+		AccountID: accountID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.GenerateHash(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if params.Scopes == nil {
+		params.Scopes = []string{}
+	}
+
+	if err = s.apiKeyRepo.CreateApiKey(ctx, params); err != nil {
+		return dto.CreatedApiKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeApiKeyCreated, accountID, "")
+
+	return dto.CreatedApiKey{
+		ApiKey: &entities.ApiKey{
+			ID:        params.ID,
+			AccountID: params.AccountID,
+			Name:      params.Name,
+			Prefix:    params.Prefix,
+			KeyHash:   params.KeyHash,
+			Scopes:    params.Scopes,
+			ExpiresAt: params.ExpiresAt,
+			CreatedAt: time.Now(),
+		},
+		Key: key,
+	}, nil
+}
+
+func (s *AuthService) ListApiKeys(ctx context.Context, accountID valueobject.ID) ([]*entities.ApiKey, error) {
+	return s.apiKeyRepo.ListApiKeysByAccountID(ctx, accountID)
+}
+
+func (s *AuthService) RevokeApiKey(ctx context.Context, accountID valueobject.ID, keyID valueobject.ID) error {
+	rowsAffected, err := s.apiKeyRepo.RevokeApiKey(ctx, accountID, keyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return appErrors.ErrApiKeyNotFound
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeApiKeyRevoked, accountID, "")
+
+	return nil
+}
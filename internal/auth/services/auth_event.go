@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+const (
+	defaultAuthEventsPageSize = 50
+	maxAuthEventsPageSize     = 200
+)
+
+// ListAuthEvents pages through the audit log newest first. The page token is
+// an opaque keyset cursor over (created_at, id), so pages stay stable while
+// new events are appended.
+func (s *AuthService) ListAuthEvents(ctx context.Context, accountID *valueobject.ID, from *time.Time, to *time.Time, pageSize int32, pageToken string) ([]*entities.AuthEvent, string, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, "", appErrors.ErrInvalidTimeRange
+	}
+
+	if pageSize <= 0 {
+		pageSize = defaultAuthEventsPageSize
+	}
+	pageSize = min(pageSize, maxAuthEventsPageSize)
+
+	params := dto.ListAuthEventsParams{
+		AccountID: accountID,
+		From:      from,
+		To:        to,
+		PageSize:  pageSize + 1,
+	}
+
+	if pageToken != "" {
+		createdAt, id, err := decodeAuthEventsPageToken(pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		params.CursorCreatedAt = &createdAt
+		params.CursorID = &id
+	}
+
+	events, err := s.authEventRepo.ListAuthEvents(ctx, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list auth events: %w", err)
+	}
+
+	if len(events) <= int(pageSize) {
+		return events, "", nil
+	}
+
+	events = events[:pageSize]
+	last := events[len(events)-1]
+
+	return events, encodeAuthEventsPageToken(last.CreatedAt, last.ID), nil
+}
+
+func (s *AuthService) recordAuthEvent(ctx context.Context, eventType valueobject.AuthEventType, accountID valueobject.ID, clientID string) {
+	s.auditRecorder.Record(ctx, dto.AuthEvent{
+		Type:      eventType,
+		AccountID: &accountID,
+		ClientID:  clientID,
+		Outcome:   valueobject.AuthEventOutcomeSuccess,
+	})
+}
+
+func encodeAuthEventsPageToken(createdAt time.Time, id valueobject.ID) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + ":" + id.ToString()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuthEventsPageToken(pageToken string) (time.Time, valueobject.ID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(pageToken)
+	if err != nil {
+		return time.Time{}, valueobject.ID{}, appErrors.ErrInvalidPageToken
+	}
+
+	micros, rawID, found := strings.Cut(string(raw), ":")
+	if !found {
+		return time.Time{}, valueobject.ID{}, appErrors.ErrInvalidPageToken
+	}
+
+	unixMicro, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, valueobject.ID{}, appErrors.ErrInvalidPageToken
+	}
+
+	id, err := valueobject.NewIDFromString(rawID)
+	if err != nil {
+		return time.Time{}, valueobject.ID{}, appErrors.ErrInvalidPageToken
+	}
+
+	return time.UnixMicro(unixMicro), id, nil
+}
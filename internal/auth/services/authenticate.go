@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+	"github.com/teacinema-go/core/logger"
+)
+
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*entities.Principal, error) {
+	if IsApiKey(credential) {
+		return s.authenticateApiKey(ctx, credential)
+	}
+
+	return s.authenticateAccessToken(ctx, credential)
+}
+
+func (s *AuthService) authenticateAccessToken(ctx context.Context, accessToken string) (*entities.Principal, error) {
+	claims, err := s.tokenManager.Parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.TokenVerified(tokenTypeAccess, claims.KeyID, s.secretKeys.IsPrimary(claims.KeyID))
+
+	if err = s.checkRevocation(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	principal := &entities.Principal{
+		Subject:  claims.Subject,
+		ClientID: claims.ClientID,
+		Scopes:   strings.Fields(claims.Scope),
+	}
+
+	switch claims.SubjectType {
+	case token.SubjectTypeAccount:
+		accountID, err := valueobject.NewIDFromString(claims.Subject)
+		if err != nil {
+			return nil, appErrors.ErrInvalidAccessToken
+		}
+		principal.AccountID = &accountID
+	case token.SubjectTypeClient:
+	default:
+		return nil, appErrors.ErrInvalidAccessToken
+	}
+
+	if claims.Actor != nil {
+		actorID, err := valueobject.NewIDFromString(claims.Actor.Subject)
+		if err != nil {
+			return nil, appErrors.ErrInvalidAccessToken
+		}
+		principal.ActorID = &actorID
+	}
+
+	return principal, nil
+}
+
+func (s *AuthService) authenticateApiKey(ctx context.Context, key string) (*entities.Principal, error) {
+	apiKey, err := s.apiKeyRepo.GetActiveApiKeyByHash(ctx, utils.GenerateHash(key))
+	if err != nil {
+		if errors.Is(err, appErrors.ErrApiKeyNotFound) {
+			return nil, appErrors.ErrInvalidApiKey
+		}
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, apiKey.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The account's role may have lost permissions since the key was created.
+	permissions, err := s.roleRepo.ListPermissionsByRole(ctx, account.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.apiKeyRepo.UpdateApiKeyLastUsedAt(ctx, apiKey.ID); err != nil {
+		logger.Warn("failed to update api key last used at", "api_key_id", apiKey.ID.ToString(), "error", err)
+	}
+
+	return &entities.Principal{
+		Subject:   apiKey.AccountID.ToString(),
+		AccountID: &apiKey.AccountID,
+		ApiKeyID:  &apiKey.ID,
+		Scopes:    intersectScopes(permissions, apiKey.Scopes),
+	}, nil
+}
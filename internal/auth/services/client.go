@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+const (
+	defaultAccessTokenTTL  = 40 * time.Minute
+	defaultRefreshTokenTTL = 14 * 24 * time.Hour
+)
+
+func (s *AuthService) ResolveClient(ctx context.Context, clientID string, grantType valueobject.GrantType) (*entities.Client, error) {
+	if clientID == "" {
+		return nil, appErrors.ErrClientNotFound
+	}
+
+	client, err := s.clientRepo.GetClientByID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowsGrantType(grantType) {
+		return nil, appErrors.ErrGrantTypeNotAllowed
+	}
+
+	return client, nil
+}
+
+func accessTokenTTL(client *entities.Client) time.Duration {
+	if client.AccessTokenTTL != nil {
+		return *client.AccessTokenTTL
+	}
+
+	return defaultAccessTokenTTL
+}
+
+func refreshTokenTTL(client *entities.Client) time.Duration {
+	if client.RefreshTokenTTL != nil {
+		return *client.RefreshTokenTTL
+	}
+
+	return defaultRefreshTokenTTL
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+)
+
+const defaultClientCredentialsTokenTTL = 10 * time.Minute
+
+func (s *AuthService) IssueClientCredentialsToken(ctx context.Context, credentials dto.ClientCredentials) (dto.ClientAccessToken, error) {
+	client, err := s.ResolveClient(ctx, credentials.ClientID, valueobject.GrantTypeClientCredentials)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrClientNotFound) {
+			return dto.ClientAccessToken{}, appErrors.ErrInvalidClient
+		}
+		return dto.ClientAccessToken{}, err
+	}
+
+	if err = s.authenticateClient(ctx, client, credentials); err != nil {
+		return dto.ClientAccessToken{}, err
+	}
+
+	scopes := credentials.Scopes
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return dto.ClientAccessToken{}, appErrors.ErrInvalidScope
+	}
+
+	tokenID, err := valueobject.NewID()
+	if err != nil {
+		return dto.ClientAccessToken{}, err
+	}
+
+	claims := token.Claims{
+		SubjectType: token.SubjectTypeClient,
+		ClientID:    client.ID,
+		Scope:       strings.Join(scopes, " "),
+	}
+	claims.ID = tokenID.ToString()
+	claims.Subject = client.ID
+
+	ttl := clientCredentialsTokenTTL(client)
+	accessToken, err := s.signAccessToken(ctx, claims, ttl)
+	if err != nil {
+		return dto.ClientAccessToken{}, err
+	}
+
+	s.metrics.TokenIssued(valueobject.GrantTypeClientCredentials)
+
+	return dto.ClientAccessToken{
+		AccessToken: accessToken,
+		ExpiresIn:   int32(ttl.Seconds()),
+		Scopes:      scopes,
+	}, nil
+}
+
+func (s *AuthService) authenticateClient(ctx context.Context, client *entities.Client, credentials dto.ClientCredentials) error {
+	switch {
+	case credentials.ClientAssertion != "":
+		if client.PublicKey == nil {
+			return appErrors.ErrInvalidClient
+		}
+
+		claims, err := token.VerifyClientAssertion(credentials.ClientAssertion, *client.PublicKey, client.ID, s.tokenManager.Issuer())
+		if err != nil {
+			return err
+		}
+
+		// Each assertion may only be used once within its lifetime. SetNX
+		// makes concurrent requests with the same assertion race for a
+		// single slot.
+		key := fmt.Sprintf("client_assertion:%s:%s", client.ID, claims.ID)
+		stored, err := s.cache.SetNX(ctx, key, 1, time.Until(claims.ExpiresAt.Time)+time.Minute)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			return appErrors.ErrInvalidClient
+		}
+
+		return nil
+	case credentials.ClientSecret != "":
+		if client.SecretHash == nil {
+			return appErrors.ErrInvalidClient
+		}
+
+		hash := utils.GenerateHash(credentials.ClientSecret)
+		if !hmac.Equal([]byte(hash), []byte(*client.SecretHash)) {
+			return appErrors.ErrInvalidClient
+		}
+
+		return nil
+	default:
+		return appErrors.ErrInvalidClient
+	}
+}
+
+func clientCredentialsTokenTTL(client *entities.Client) time.Duration {
+	if client.AccessTokenTTL != nil {
+		return *client.AccessTokenTTL
+	}
+
+	return defaultClientCredentialsTokenTTL
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+const (
+	impersonationTokenTTL      = 15 * time.Minute
+	impersonationMaxReasonSize = 500
+)
+
+func (s *AuthService) Impersonate(ctx context.Context, actorID valueobject.ID, clientID string, targetID valueobject.ID, reason string) (dto.ImpersonationToken, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > impersonationMaxReasonSize {
+		return dto.ImpersonationToken{}, appErrors.ErrInvalidReason
+	}
+
+	if actorID == targetID {
+		return dto.ImpersonationToken{}, appErrors.ErrInvalidImpersonation
+	}
+
+	target, err := s.accountRepo.GetAccountByID(ctx, targetID)
+	if err != nil {
+		return dto.ImpersonationToken{}, err
+	}
+
+	impersonationID, err := valueobject.NewID()
+	if err != nil {
+		return dto.ImpersonationToken{}, err
+	}
+
+	// The audit record is written before the token is signed, so no token
+	// can exist without a matching trail entry.
+	err = s.impersonationRepo.CreateImpersonation(ctx, dto.CreateImpersonationParams{
+		ID:              impersonationID,
+		ActorAccountID:  actorID,
+		TargetAccountID: target.ID,
+		ClientID:        clientID,
+		Reason:          reason,
+		ExpiresAt:       time.Now().Add(impersonationTokenTTL),
+	})
+	if err != nil {
+		return dto.ImpersonationToken{}, fmt.Errorf("failed to record impersonation: %w", err)
+	}
+
+	claims := token.Claims{
+		SubjectType: token.SubjectTypeAccount,
+		ClientID:    clientID,
+		Actor:       &token.Actor{Subject: actorID.ToString()},
+	}
+	claims.ID = impersonationID.ToString()
+	claims.Subject = target.ID.ToString()
+
+	accessToken, err := s.signAccessToken(ctx, claims, impersonationTokenTTL)
+	if err != nil {
+		return dto.ImpersonationToken{}, err
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeImpersonation, target.ID, clientID)
+
+	return dto.ImpersonationToken{
+		AccessToken: accessToken,
+		ExpiresIn:   int32(impersonationTokenTTL.Seconds()),
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
 	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 )
 
@@ -17,26 +18,95 @@ type AccountRepository interface {
 	GetAccountByID(ctx context.Context, accountID valueobject.ID) (*entities.Account, error)
 	AccountExistsByEmail(ctx context.Context, email valueobject.Identifier) (bool, error)
 	AccountExistsByPhone(ctx context.Context, phone valueobject.Identifier) (bool, error)
+	UpdateAccountRole(ctx context.Context, accountID valueobject.ID, role valueobject.Role) error
 }
 
 type RefreshTokenRepository interface {
 	CreateRefreshToken(ctx context.Context, arg dto.CreateRefreshTokenParams) error
 	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entities.RefreshToken, error)
 	DeleteRefreshTokenByHash(ctx context.Context, tokenHash string) (int64, error)
+	DeleteRefreshTokenByHashAndClientID(ctx context.Context, tokenHash string, clientID string) (int64, error)
 	DeleteRefreshTokensByAccountID(ctx context.Context, accountID uuid.UUID) error
 }
 
+type ClientRepository interface {
+	GetClientByID(ctx context.Context, clientID string) (*entities.Client, error)
+	GetLongestAccessTokenTTL(ctx context.Context) (time.Duration, error)
+}
+
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, arg dto.CreateApiKeyParams) error
+	GetActiveApiKeyByHash(ctx context.Context, keyHash string) (*entities.ApiKey, error)
+	ListApiKeysByAccountID(ctx context.Context, accountID valueobject.ID) ([]*entities.ApiKey, error)
+	RevokeApiKey(ctx context.Context, accountID valueobject.ID, keyID valueobject.ID) (int64, error)
+	UpdateApiKeyLastUsedAt(ctx context.Context, keyID valueobject.ID) error
+}
+
+type RoleRepository interface {
+	ListRoles(ctx context.Context) ([]*entities.Role, error)
+	RoleExists(ctx context.Context, role valueobject.Role) (bool, error)
+	CreateRole(ctx context.Context, role valueobject.Role, description string) error
+	DeleteRole(ctx context.Context, role valueobject.Role) error
+	ListPermissions(ctx context.Context) ([]*entities.Permission, error)
+	ListPermissionsByRole(ctx context.Context, role valueobject.Role) ([]valueobject.Permission, error)
+	CountAccountsWithPermission(ctx context.Context, permission valueobject.Permission) (int64, error)
+	SetRolePermissions(ctx context.Context, role valueobject.Role, permissions []valueobject.Permission) error
+}
+
+type ImpersonationRepository interface {
+	CreateImpersonation(ctx context.Context, arg dto.CreateImpersonationParams) error
+}
+
+type OutboxRepository interface {
+	CreateOutboxEvent(ctx context.Context, arg dto.CreateOutboxEventParams) error
+}
+
+type WebhookRepository interface {
+	CreateWebhookSubscription(ctx context.Context, arg dto.CreateWebhookSubscriptionParams) error
+	GetWebhookSubscriptionByID(ctx context.Context, subscriptionID valueobject.ID) (*entities.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID valueobject.ID) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID valueobject.ID, status *valueobject.WebhookDeliveryStatus, limit int32) ([]*entities.WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID valueobject.ID) ([]*entities.WebhookDeliveryAttempt, error)
+	ReplayWebhookDelivery(ctx context.Context, deliveryID valueobject.ID) error
+}
+
+type AuthEventRepository interface {
+	ListAuthEvents(ctx context.Context, arg dto.ListAuthEventsParams) ([]*entities.AuthEvent, error)
+}
+
+type AuditRecorder interface {
+	Record(ctx context.Context, event dto.AuthEvent)
+}
+
+type Metrics interface {
+	OtpSent(identifierType valueobject.IdentifierType)
+	OtpVerified(identifierType valueobject.IdentifierType)
+	OtpFailed(identifierType valueobject.IdentifierType)
+	OtpExpired(identifierType valueobject.IdentifierType)
+	TokenIssued(grantType valueobject.GrantType)
+	TokenRotated()
+	TokenRevoked(scope string)
+	TokenVerified(tokenType string, keyID string, primary bool)
+}
+
 type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
 	Delete(ctx context.Context, key string) error
 }
 
-type TxManager interface {
-	WithTransaction(ctx context.Context, fn func(repos TxRepositories) (any, error)) (any, error)
+type RevocationList interface {
+	RevokeToken(ctx context.Context, subject string, tokenID string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, sessionID string, notBefore time.Time, expiresAt time.Time) error
+	RevokeSubject(ctx context.Context, subject string, notBefore time.Time, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, claims *token.Claims) (bool, error)
 }
 
-type TxRepositories interface {
-	Account() AccountRepository
-	RefreshToken() RefreshTokenRepository
+// TxManager may call fn more than once when the transaction has to be
+// retried, so fn must not have side effects outside the database other than
+// idempotent writes, such as revocation entries.
+type TxManager interface {
+	WithTransaction(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error
 }
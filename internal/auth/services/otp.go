@@ -21,7 +21,13 @@ func (s *AuthService) GenerateOtp(ctx context.Context, identifier valueobject.Id
 
 	hash := utils.GenerateHash(otp)
 	key := fmt.Sprintf("otp:%s:%s", identifierType, identifier)
-	return otp, s.cache.Set(ctx, key, hash, 5*time.Minute)
+	if err = s.cache.Set(ctx, key, hash, 5*time.Minute); err != nil {
+		return otp, err
+	}
+
+	s.metrics.OtpSent(identifierType)
+
+	return otp, nil
 }
 
 func (s *AuthService) VerifyOtp(ctx context.Context, otp string, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (bool, error) {
@@ -29,6 +35,7 @@ func (s *AuthService) VerifyOtp(ctx context.Context, otp string, identifier valu
 	val, err := s.cache.Get(ctx, key)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
+			s.metrics.OtpExpired(identifierType)
 			return false, appErrors.ErrNotFound
 		}
 		return false, err
@@ -37,8 +44,11 @@ func (s *AuthService) VerifyOtp(ctx context.Context, otp string, identifier valu
 
 	if hmac.Equal([]byte(hash), []byte(val)) {
 		_ = s.cache.Delete(ctx, key)
+		s.metrics.OtpVerified(identifierType)
 		return true, nil
 	}
 
+	s.metrics.OtpFailed(identifierType)
+
 	return false, nil
 }
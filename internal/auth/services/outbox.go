@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+// enqueueAccountEvent must be called with the ctx of the transaction that
+// performs the state change, so the event is published if and only if that
+// change commits.
+func (s *AuthService) enqueueAccountEvent(ctx context.Context, accountID valueobject.ID, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	eventID, err := valueobject.NewID()
+	if err != nil {
+		return err
+	}
+
+	err = s.outboxRepo.CreateOutboxEvent(ctx, dto.CreateOutboxEventParams{
+		ID:            eventID,
+		AggregateType: events.AggregateTypeAccount,
+		AggregateID:   accountID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", eventType, err)
+	}
+
+	return nil
+}
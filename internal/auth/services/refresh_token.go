@@ -2,81 +2,203 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
 	"github.com/teacinema-go/auth-service/pkg/utils"
 	"github.com/teacinema-go/passport"
 )
 
-func (s *AuthService) VerifyToken(token *passport.Token) bool {
-	return token.VerifyToken(s.secretKey)
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
+// VerifyToken checks a refresh token against every secret key, primary
+// first, since refresh tokens do not record which key signed them.
+func (s *AuthService) VerifyToken(refreshToken *passport.Token) bool {
+	for _, key := range s.secretKeys.All() {
+		if refreshToken.VerifyToken(key.Secret) {
+			s.metrics.TokenVerified(tokenTypeRefresh, key.ID, s.secretKeys.IsPrimary(key.ID))
+			return true
+		}
+	}
+
+	return false
 }
 
-func (s *AuthService) RotateRefreshToken(ctx context.Context, oldToken *passport.Token) (dto.Tokens, error) {
-	res, err := s.txManager.WithTransaction(ctx, func(repos TxRepositories) (any, error) {
+func (s *AuthService) RotateRefreshToken(ctx context.Context, client *entities.Client, oldToken *passport.Token) (dto.Tokens, error) {
+	tokens, err := WithTx(ctx, s.txManager, TxOptions{}, func(ctx context.Context) (dto.Tokens, error) {
 		oldHash := utils.GenerateHash(oldToken.Val)
 
-		rowsAffected, err := repos.RefreshToken().DeleteRefreshTokenByHash(ctx, oldHash)
+		// The new refresh token continues the session, so revoking it on
+		// logout also covers access tokens issued before the rotation.
+		session, err := s.refreshTokenRepo.GetRefreshTokenByHash(ctx, oldHash)
 		if err != nil {
-			return nil, fmt.Errorf("failed to delete old refresh token: %w", err)
+			if errors.Is(err, appErrors.ErrRefreshTokenNotFound) {
+				return dto.Tokens{}, appErrors.ErrInvalidRefreshToken
+			}
+			return dto.Tokens{}, fmt.Errorf("failed to get refresh token: %w", err)
+		}
+
+		rowsAffected, err := s.refreshTokenRepo.DeleteRefreshTokenByHashAndClientID(ctx, oldHash, client.ID)
+		if err != nil {
+			return dto.Tokens{}, fmt.Errorf("failed to delete old refresh token: %w", err)
 		}
 
 		if rowsAffected == 0 {
-			return nil, appErrors.ErrInvalidRefreshToken
+			return dto.Tokens{}, appErrors.ErrInvalidRefreshToken
+		}
+
+		accountID, err := valueobject.NewIDFromString(oldToken.UserID)
+		if err != nil {
+			return dto.Tokens{}, fmt.Errorf("failed to parse account ID: %w", err)
 		}
 
-		tokenID, err := uuid.NewV7()
+		account, err := s.accountRepo.GetAccountByID(ctx, accountID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate token ID: %w", err)
+			return dto.Tokens{}, fmt.Errorf("failed to get account: %w", err)
 		}
 
-		newRefreshToken := passport.GenerateToken(s.secretKey, oldToken.UserID, refreshTokenTTL)
+		return s.issueTokens(ctx, client, account.ID, account.Role, session.ID.ToUUID())
+	})
+	if err != nil {
+		return dto.Tokens{}, err
+	}
+
+	s.metrics.TokenIssued(valueobject.GrantTypeRefreshToken)
+	s.metrics.TokenRotated()
+
+	return tokens, nil
+}
+
+// Logout returns the account the session belonged to.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) (valueobject.ID, error) {
+	tokenHash := utils.GenerateHash(refreshToken)
+	notBefore := time.Now()
 
-		accountID, err := uuid.Parse(newRefreshToken.UserID)
+	var session *entities.RefreshToken
+	err := s.txManager.WithTransaction(ctx, TxOptions{}, func(ctx context.Context) error {
+		var err error
+		session, err = s.refreshTokenRepo.GetRefreshTokenByHash(ctx, tokenHash)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse account ID: %w", err)
+			if errors.Is(err, appErrors.ErrRefreshTokenNotFound) {
+				return appErrors.ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get refresh token: %w", err)
 		}
 
-		err = repos.RefreshToken().CreateRefreshToken(ctx, dto.CreateRefreshTokenParams{
-			ID:        tokenID,
-			AccountID: accountID,
-			TokenHash: utils.GenerateHash(newRefreshToken.Val),
-			ExpiresAt: time.Unix(newRefreshToken.Exp, 0),
-		})
+		rowsAffected, err := s.refreshTokenRepo.DeleteRefreshTokenByHash(ctx, tokenHash)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create new refresh token: %w", err)
+			return fmt.Errorf("failed to delete refresh token: %w", err)
 		}
-		newAccessToken := passport.GenerateToken(s.secretKey, oldToken.UserID, accessTokenTTL)
 
-		return &dto.Tokens{
-			AccessToken:  newAccessToken.Val,
-			RefreshToken: newRefreshToken.Val,
-			ExpiresIn:    int32(accessTokenTTL.Seconds()),
-		}, nil
-	})
+		if rowsAffected == 0 {
+			return appErrors.ErrInvalidRefreshToken
+		}
+
+		payload := events.SessionRevoked{
+			AccountID:  session.AccountID.String(),
+			SessionID:  session.ID.ToString(),
+			OccurredAt: notBefore,
+		}
+		if session.ClientID != nil {
+			payload.ClientID = *session.ClientID
+		}
 
+		accountID := valueobject.ID(session.AccountID)
+		if err = s.enqueueAccountEvent(ctx, accountID, events.TypeSessionRevoked, payload); err != nil {
+			return err
+		}
+
+		expiresAt, err := s.revocationExpiry(ctx, notBefore)
+		if err != nil {
+			return err
+		}
+
+		if err = s.enqueueRevocationEvent(ctx, accountID, events.AccessTokenRevoked{
+			AccountID:  payload.AccountID,
+			SessionID:  payload.SessionID,
+			NotBefore:  &notBefore,
+			ExpiresAt:  expiresAt,
+			OccurredAt: notBefore,
+		}); err != nil {
+			return err
+		}
+
+		// Written before the commit, like RevokeAccountTokens does.
+		return s.revocationList.RevokeSession(ctx, session.ID.ToString(), notBefore, expiresAt)
+	})
 	if err != nil {
-		return dto.Tokens{}, err
+		return valueobject.ID{}, err
 	}
 
-	return res.(dto.Tokens), nil
+	s.metrics.TokenRevoked(revocationScopeSession)
+
+	return valueobject.ID(session.AccountID), nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
-	tokenHash := utils.GenerateHash(refreshToken)
+// issueTokens continues the session sessionID, or starts a new one when it
+// is uuid.Nil.
+func (s *AuthService) issueTokens(ctx context.Context, client *entities.Client, accountID valueobject.ID, role valueobject.Role, sessionID uuid.UUID) (dto.Tokens, error) {
+	permissions, err := s.roleRepo.ListPermissionsByRole(ctx, role)
+	if err != nil {
+		return dto.Tokens{}, fmt.Errorf("failed to get role permissions: %w", err)
+	}
 
-	rowsAffected, err := s.refreshTokenRepo.DeleteRefreshTokenByHash(ctx, tokenHash)
+	if sessionID == uuid.Nil {
+		sessionID, err = uuid.NewV7()
+		if err != nil {
+			return dto.Tokens{}, fmt.Errorf("failed to generate session ID: %w", err)
+		}
+	}
+
+	accessTokenID, err := valueobject.NewID()
 	if err != nil {
-		return fmt.Errorf("failed to delete refresh token: %w", err)
+		return dto.Tokens{}, err
 	}
 
-	if rowsAffected == 0 {
-		return appErrors.ErrInvalidRefreshToken
+	refreshTTL := refreshTokenTTL(client)
+	refreshToken := passport.GenerateToken(s.secretKeys.Primary.Secret, accountID.ToString(), refreshTTL)
+	err = s.refreshTokenRepo.CreateRefreshToken(ctx, dto.CreateRefreshTokenParams{
+		ID:        sessionID,
+		AccountID: accountID.ToUUID(),
+		ClientID:  client.ID,
+		TokenHash: utils.GenerateHash(refreshToken.Val),
+		ExpiresAt: time.Unix(refreshToken.Exp, 0),
+	})
+	if err != nil {
+		return dto.Tokens{}, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	claims := token.Claims{
+		SubjectType: token.SubjectTypeAccount,
+		ClientID:    client.ID,
+		Scope:       strings.Join(intersectScopes(permissions, client.Scopes), " "),
+	}
+	claims.ID = accessTokenID.ToString()
+	claims.Subject = accountID.ToString()
+	claims.SessionID = sessionID.String()
+
+	ttl := accessTokenTTL(client)
+	accessToken, err := s.signAccessToken(ctx, claims, ttl)
+	if err != nil {
+		return dto.Tokens{}, err
 	}
 
-	return nil
+	return dto.Tokens{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken.Val,
+		ExpiresIn:        int32(ttl.Seconds()),
+		RefreshExpiresIn: int32(refreshTTL.Seconds()),
+	}, nil
 }
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+const (
+	revocationScopeSession = "session"
+	revocationScopeAccount = "account"
+	revocationScopeToken   = "token"
+)
+
+// IntrospectToken reports whether an access token is still accepted. Tokens
+// that are malformed, expired or revoked are inactive rather than an error.
+func (s *AuthService) IntrospectToken(ctx context.Context, accessToken string) (dto.TokenIntrospection, error) {
+	claims, err := s.tokenManager.Parse(accessToken)
+	if err != nil {
+		return dto.TokenIntrospection{}, nil
+	}
+
+	if err = s.checkRevocation(ctx, claims); err != nil {
+		if errors.Is(err, appErrors.ErrRevokedAccessToken) {
+			return dto.TokenIntrospection{}, nil
+		}
+		return dto.TokenIntrospection{}, err
+	}
+
+	introspection := dto.TokenIntrospection{
+		Active:    true,
+		Subject:   claims.Subject,
+		ClientID:  claims.ClientID,
+		Scopes:    strings.Fields(claims.Scope),
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.Actor != nil {
+		introspection.ActorID = claims.Actor.Subject
+	}
+
+	return introspection, nil
+}
+
+// RevokeAccountTokens signs the account out everywhere: its refresh tokens
+// are deleted and no access token issued to it so far is accepted anymore.
+func (s *AuthService) RevokeAccountTokens(ctx context.Context, accountID valueobject.ID) error {
+	notBefore := time.Now()
+
+	err := s.txManager.WithTransaction(ctx, TxOptions{}, func(ctx context.Context) error {
+		if _, err := s.accountRepo.GetAccountByID(ctx, accountID); err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepo.DeleteRefreshTokensByAccountID(ctx, accountID.ToUUID()); err != nil {
+			return fmt.Errorf("failed to delete refresh tokens: %w", err)
+		}
+
+		expiresAt, err := s.revocationExpiry(ctx, notBefore)
+		if err != nil {
+			return err
+		}
+
+		if err = s.enqueueRevocationEvent(ctx, accountID, events.AccessTokenRevoked{
+			AccountID:  accountID.ToString(),
+			NotBefore:  &notBefore,
+			ExpiresAt:  expiresAt,
+			OccurredAt: notBefore,
+		}); err != nil {
+			return err
+		}
+
+		// Written before the commit, so a failed write rolls the revocation
+		// back instead of failing a call whose work was committed.
+		return s.revocationList.RevokeSubject(ctx, accountID.ToString(), notBefore, expiresAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeTokensRevoked, accountID, "")
+	s.metrics.TokenRevoked(revocationScopeAccount)
+
+	return nil
+}
+
+// RevokeAccessToken revokes a single access token of the account by its jti.
+// The entry is scoped to the account, so the jti of a token issued to anyone
+// else revokes nothing. The token's expiry is unknown here, so the entry is
+// kept for the longest access token TTL.
+func (s *AuthService) RevokeAccessToken(ctx context.Context, accountID valueobject.ID, tokenID valueobject.ID) error {
+	now := time.Now()
+
+	err := s.txManager.WithTransaction(ctx, TxOptions{}, func(ctx context.Context) error {
+		if _, err := s.accountRepo.GetAccountByID(ctx, accountID); err != nil {
+			return err
+		}
+
+		expiresAt, err := s.revocationExpiry(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		if err = s.enqueueRevocationEvent(ctx, accountID, events.AccessTokenRevoked{
+			AccountID:  accountID.ToString(),
+			TokenID:    tokenID.ToString(),
+			ExpiresAt:  expiresAt,
+			OccurredAt: now,
+		}); err != nil {
+			return err
+		}
+
+		return s.revocationList.RevokeToken(ctx, accountID.ToString(), tokenID.ToString(), expiresAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeTokensRevoked, accountID, "")
+	s.metrics.TokenRevoked(revocationScopeToken)
+
+	return nil
+}
+
+// revocationExpiry returns when no access token issued up to now can still
+// be valid, so its revocation entry can go.
+func (s *AuthService) revocationExpiry(ctx context.Context, now time.Time) (time.Time, error) {
+	ttl, err := s.clientRepo.GetLongestAccessTokenTTL(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get longest access token TTL: %w", err)
+	}
+
+	return now.Add(max(ttl, defaultAccessTokenTTL, defaultClientCredentialsTokenTTL, impersonationTokenTTL)), nil
+}
+
+// checkRevocation fails closed: while the revocation list cannot be read,
+// no access token is accepted, rather than letting revoked ones through.
+func (s *AuthService) checkRevocation(ctx context.Context, claims *token.Claims) error {
+	revoked, err := s.revocationList.IsRevoked(ctx, claims)
+	if err != nil {
+		return fmt.Errorf("%w: %w", appErrors.ErrRevocationUnavailable, err)
+	}
+
+	if revoked {
+		return appErrors.ErrRevokedAccessToken
+	}
+
+	return nil
+}
+
+// enqueueRevocationEvent feeds the revocation list to other services when
+// revocation events are enabled.
+func (s *AuthService) enqueueRevocationEvent(ctx context.Context, accountID valueobject.ID, payload events.AccessTokenRevoked) error {
+	if !s.revocationEvents {
+		return nil
+	}
+
+	return s.enqueueAccountEvent(ctx, accountID, events.TypeAccessTokenRevoked, payload)
+}
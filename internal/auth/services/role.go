@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+func (s *AuthService) ListRoles(ctx context.Context) ([]*entities.Role, error) {
+	return s.roleRepo.ListRoles(ctx)
+}
+
+func (s *AuthService) ListPermissions(ctx context.Context) ([]*entities.Permission, error) {
+	return s.roleRepo.ListPermissions(ctx)
+}
+
+func (s *AuthService) CreateRole(ctx context.Context, role valueobject.Role, description string, permissions []valueobject.Permission) error {
+	if err := role.Validate(); err != nil {
+		return err
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return err
+	}
+
+	return s.txManager.WithTransaction(ctx, TxOptions{}, func(ctx context.Context) error {
+		if err := s.roleRepo.CreateRole(ctx, role, description); err != nil {
+			return err
+		}
+
+		return s.roleRepo.SetRolePermissions(ctx, role, permissions)
+	})
+}
+
+func (s *AuthService) DeleteRole(ctx context.Context, role valueobject.Role) error {
+	if role.IsBuiltin() {
+		return appErrors.ErrBuiltinRole
+	}
+
+	return s.roleRepo.DeleteRole(ctx, role)
+}
+
+// SetRolePermissions refuses to take roles:write away from the actor's own
+// role, since the actor could then no longer undo the change.
+func (s *AuthService) SetRolePermissions(ctx context.Context, actorID *valueobject.ID, role valueobject.Role, permissions []valueobject.Permission) error {
+	if err := validatePermissions(permissions); err != nil {
+		return err
+	}
+
+	if actorID != nil && !slices.Contains(permissions, valueobject.PermissionRolesWrite) {
+		actor, err := s.accountRepo.GetAccountByID(ctx, *actorID)
+		if err != nil {
+			return err
+		}
+		if actor.Role == role {
+			return appErrors.ErrRoleLockout
+		}
+	}
+
+	return s.txManager.WithTransaction(ctx, TxOptions{}, func(ctx context.Context) error {
+		exists, err := s.roleRepo.RoleExists(ctx, role)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return appErrors.ErrRoleNotFound
+		}
+
+		return s.roleRepo.SetRolePermissions(ctx, role, permissions)
+	})
+}
+
+// SetAccountRole refuses to take roles:write away from the actor or from the
+// last account holding it, since nobody could undo the change. The account's
+// access tokens are revoked, so its scopes follow the new role once it
+// refreshes them.
+func (s *AuthService) SetAccountRole(ctx context.Context, actorID *valueobject.ID, accountID valueobject.ID, role valueobject.Role) error {
+	exists, err := s.roleRepo.RoleExists(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return appErrors.ErrRoleNotFound
+	}
+
+	notBefore := time.Now()
+
+	// Serializable, so two concurrent demotions cannot both count the other
+	// account as the remaining holder.
+	err = s.txManager.WithTransaction(ctx, TxOptions{Isolation: IsolationSerializable}, func(ctx context.Context) error {
+		if err := s.checkRoleLockout(ctx, actorID, accountID, role); err != nil {
+			return err
+		}
+
+		if err := s.accountRepo.UpdateAccountRole(ctx, accountID, role); err != nil {
+			return err
+		}
+
+		if err := s.enqueueAccountEvent(ctx, accountID, events.TypeAccountRoleChanged, events.AccountRoleChanged{
+			AccountID:  accountID.ToString(),
+			Role:       role,
+			OccurredAt: notBefore,
+		}); err != nil {
+			return err
+		}
+
+		expiresAt, err := s.revocationExpiry(ctx, notBefore)
+		if err != nil {
+			return err
+		}
+
+		if err = s.enqueueRevocationEvent(ctx, accountID, events.AccessTokenRevoked{
+			AccountID:  accountID.ToString(),
+			NotBefore:  &notBefore,
+			ExpiresAt:  expiresAt,
+			OccurredAt: notBefore,
+		}); err != nil {
+			return err
+		}
+
+		return s.revocationList.RevokeSubject(ctx, accountID.ToString(), notBefore, expiresAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordAuthEvent(ctx, valueobject.AuthEventTypeAccountRoleChanged, accountID, "")
+	s.metrics.TokenRevoked(revocationScopeAccount)
+
+	return nil
+}
+
+// checkRoleLockout returns ErrRoleLockout when moving the account to role
+// takes roles:write away from the actor or from its last holder.
+func (s *AuthService) checkRoleLockout(ctx context.Context, actorID *valueobject.ID, accountID valueobject.ID, role valueobject.Role) error {
+	permissions, err := s.roleRepo.ListPermissionsByRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(permissions, valueobject.PermissionRolesWrite) {
+		return nil
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	current, err := s.roleRepo.ListPermissionsByRole(ctx, account.Role)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(current, valueobject.PermissionRolesWrite) {
+		return nil
+	}
+
+	if actorID != nil && *actorID == accountID {
+		return appErrors.ErrRoleLockout
+	}
+
+	holders, err := s.roleRepo.CountAccountsWithPermission(ctx, valueobject.PermissionRolesWrite)
+	if err != nil {
+		return err
+	}
+	if holders <= 1 {
+		return appErrors.ErrRoleLockout
+	}
+
+	return nil
+}
+
+func validatePermissions(permissions []valueobject.Permission) error {
+	for _, permission := range permissions {
+		if err := permission.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func intersectScopes(permissions []valueobject.Permission, allowed []string) []string {
+	scopes := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if slices.Contains(allowed, string(permission)) {
+			scopes = append(scopes, string(permission))
+		}
+	}
+
+	return scopes
+}
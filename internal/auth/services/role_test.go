@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+// fakeRoleStore keeps accounts and role permissions in memory. Calling a
+// method it does not implement panics through the nil embedded interfaces.
+type fakeRoleStore struct {
+	AccountRepository
+	RoleRepository
+	roles       map[valueobject.Role][]valueobject.Permission
+	accountRole map[valueobject.ID]valueobject.Role
+}
+
+func (f *fakeRoleStore) GetAccountByID(_ context.Context, accountID valueobject.ID) (*entities.Account, error) {
+	role, ok := f.accountRole[accountID]
+	if !ok {
+		return nil, appErrors.ErrAccountNotFound
+	}
+	return &entities.Account{ID: accountID, Role: role}, nil
+}
+
+func (f *fakeRoleStore) ListPermissionsByRole(_ context.Context, role valueobject.Role) ([]valueobject.Permission, error) {
+	return f.roles[role], nil
+}
+
+func (f *fakeRoleStore) CountAccountsWithPermission(_ context.Context, permission valueobject.Permission) (int64, error) {
+	var count int64
+	for _, role := range f.accountRole {
+		for _, p := range f.roles[role] {
+			if p == permission {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func TestCheckRoleLockout(t *testing.T) {
+	admin, otherAdmin, user := valueobject.ID(uuid.New()), valueobject.ID(uuid.New()), valueobject.ID(uuid.New())
+	roles := map[valueobject.Role][]valueobject.Permission{
+		"admin":   {valueobject.PermissionRolesWrite, valueobject.PermissionAccountsRead},
+		"support": {valueobject.PermissionAccountsRead},
+	}
+
+	tests := []struct {
+		name        string
+		accountRole map[valueobject.ID]valueobject.Role
+		actorID     *valueobject.ID
+		accountID   valueobject.ID
+		role        valueobject.Role
+		wantErr     error
+	}{
+		{
+			name:        "self-demotion",
+			accountRole: map[valueobject.ID]valueobject.Role{admin: "admin", otherAdmin: "admin"},
+			actorID:     &admin,
+			accountID:   admin,
+			role:        "support",
+			wantErr:     appErrors.ErrRoleLockout,
+		},
+		{
+			name:        "last holder",
+			accountRole: map[valueobject.ID]valueobject.Role{admin: "admin", user: "support"},
+			actorID:     &user,
+			accountID:   admin,
+			role:        "support",
+			wantErr:     appErrors.ErrRoleLockout,
+		},
+		{
+			name:        "another holder remains",
+			accountRole: map[valueobject.ID]valueobject.Role{admin: "admin", otherAdmin: "admin"},
+			actorID:     &admin,
+			accountID:   otherAdmin,
+			role:        "support",
+		},
+		{
+			name:        "new role keeps roles:write",
+			accountRole: map[valueobject.ID]valueobject.Role{admin: "admin"},
+			actorID:     &admin,
+			accountID:   admin,
+			role:        "admin",
+		},
+		{
+			name:        "account never held roles:write",
+			accountRole: map[valueobject.ID]valueobject.Role{admin: "admin", user: "support"},
+			actorID:     &admin,
+			accountID:   user,
+			role:        "support",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRoleStore{roles: roles, accountRole: tt.accountRole}
+			s := &AuthService{accountRepo: store, roleRepo: store}
+
+			err := s.checkRoleLockout(context.Background(), tt.actorID, tt.accountID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkRoleLockout() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
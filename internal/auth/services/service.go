@@ -1,19 +1,68 @@
 package services
 
+import (
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+)
+
 type AuthService struct {
-	accountRepo      AccountRepository
-	refreshTokenRepo RefreshTokenRepository
-	cache            Cache
-	txManager        TxManager
-	secretKey        string
+	accountRepo       AccountRepository
+	refreshTokenRepo  RefreshTokenRepository
+	clientRepo        ClientRepository
+	apiKeyRepo        ApiKeyRepository
+	roleRepo          RoleRepository
+	impersonationRepo ImpersonationRepository
+	authEventRepo     AuthEventRepository
+	outboxRepo        OutboxRepository
+	webhookRepo       WebhookRepository
+	auditRecorder     AuditRecorder
+	metrics           Metrics
+	cache             Cache
+	txManager         TxManager
+	revocationList    RevocationList
+	revocationEvents  bool
+	tokenManager      *token.Manager
+	secretKeys        token.Keys
+}
+
+// Deps holds everything AuthService needs.
+type Deps struct {
+	AccountRepo       AccountRepository
+	RefreshTokenRepo  RefreshTokenRepository
+	ClientRepo        ClientRepository
+	ApiKeyRepo        ApiKeyRepository
+	RoleRepo          RoleRepository
+	ImpersonationRepo ImpersonationRepository
+	AuthEventRepo     AuthEventRepository
+	OutboxRepo        OutboxRepository
+	WebhookRepo       WebhookRepository
+	AuditRecorder     AuditRecorder
+	Metrics           Metrics
+	Cache             Cache
+	TxManager         TxManager
+	RevocationList    RevocationList
+	RevocationEvents  bool
+	SecretKeys        token.Keys
+	Issuer            string
 }
 
-func NewAuthService(accountRepo AccountRepository, refreshTokenRepo RefreshTokenRepository, cache Cache, txManager TxManager, secretKey string) *AuthService {
+func NewAuthService(deps Deps) *AuthService {
 	return &AuthService{
-		accountRepo:      accountRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		cache:            cache,
-		txManager:        txManager,
-		secretKey:        secretKey,
+		accountRepo:       deps.AccountRepo,
+		refreshTokenRepo:  deps.RefreshTokenRepo,
+		clientRepo:        deps.ClientRepo,
+		apiKeyRepo:        deps.ApiKeyRepo,
+		roleRepo:          deps.RoleRepo,
+		impersonationRepo: deps.ImpersonationRepo,
+		authEventRepo:     deps.AuthEventRepo,
+		outboxRepo:        deps.OutboxRepo,
+		webhookRepo:       deps.WebhookRepo,
+		auditRecorder:     deps.AuditRecorder,
+		metrics:           deps.Metrics,
+		cache:             deps.Cache,
+		txManager:         deps.TxManager,
+		revocationList:    deps.RevocationList,
+		revocationEvents:  deps.RevocationEvents,
+		tokenManager:      token.NewManager(deps.SecretKeys, deps.Issuer),
+		secretKeys:        deps.SecretKeys,
 	}
 }
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/teacinema-go/auth-service/internal/auth/services")
+
+func (s *AuthService) signAccessToken(ctx context.Context, claims token.Claims, ttl time.Duration) (string, error) {
+	_, span := tracer.Start(ctx, "token.sign")
+	defer span.End()
+
+	accessToken, err := s.tokenManager.Generate(claims, ttl)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return accessToken, err
+}
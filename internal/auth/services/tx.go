@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+)
+
+type IsolationLevel int
+
+const (
+	IsolationReadCommitted IsolationLevel = iota
+	IsolationRepeatableRead
+	IsolationSerializable
+)
+
+type TxOptions struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+}
+
+// WithTx runs fn in a transaction and returns its result once the
+// transaction has committed. Repositories called with the ctx passed to fn
+// join the transaction; with any other ctx they run outside of it.
+func WithTx[T any](ctx context.Context, m TxManager, opts TxOptions, fn func(ctx context.Context) (T, error)) (T, error) {
+	var res T
+	err := m.WithTransaction(ctx, opts, func(ctx context.Context) error {
+		var err error
+		res, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return res, nil
+}
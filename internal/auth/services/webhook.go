@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/events"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+)
+
+const (
+	webhookSecretBytes            = 32
+	webhookMaxURLLength           = 2048
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+func (s *AuthService) CreateWebhookSubscription(ctx context.Context, rawURL string, eventTypes []string) (dto.CreatedWebhookSubscription, error) {
+	if err := validateWebhookURL(ctx, rawURL); err != nil {
+		return dto.CreatedWebhookSubscription{}, err
+	}
+
+	for _, eventType := range eventTypes {
+		if !events.IsKnownType(eventType) {
+			return dto.CreatedWebhookSubscription{}, appErrors.ErrInvalidEventType
+		}
+	}
+	eventTypes = slices.Compact(slices.Sorted(slices.Values(eventTypes)))
+
+	subscriptionID, err := valueobject.NewID()
+	if err != nil {
+		return dto.CreatedWebhookSubscription{}, err
+	}
+
+	secret, err := utils.GenerateRandomHex(webhookSecretBytes)
+	if err != nil {
+		return dto.CreatedWebhookSubscription{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	params := dto.CreateWebhookSubscriptionParams{
+		ID:         subscriptionID,
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+	if err = s.webhookRepo.CreateWebhookSubscription(ctx, params); err != nil {
+		return dto.CreatedWebhookSubscription{}, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return dto.CreatedWebhookSubscription{
+		Subscription: &entities.WebhookSubscription{
+			ID:         params.ID,
+			URL:        params.URL,
+			EventTypes: params.EventTypes,
+			CreatedAt:  time.Now(),
+		},
+		Secret: secret,
+	}, nil
+}
+
+func (s *AuthService) ListWebhookSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error) {
+	return s.webhookRepo.ListWebhookSubscriptions(ctx)
+}
+
+func (s *AuthService) DeleteWebhookSubscription(ctx context.Context, subscriptionID valueobject.ID) error {
+	return s.webhookRepo.DeleteWebhookSubscription(ctx, subscriptionID)
+}
+
+func (s *AuthService) ListWebhookDeliveries(ctx context.Context, subscriptionID valueobject.ID, status *valueobject.WebhookDeliveryStatus, limit int32) ([]*entities.WebhookDelivery, error) {
+	if status != nil {
+		if err := status.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	limit = min(limit, maxWebhookDeliveriesLimit)
+
+	if _, err := s.webhookRepo.GetWebhookSubscriptionByID(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.ListWebhookDeliveries(ctx, subscriptionID, status, limit)
+}
+
+func (s *AuthService) ListWebhookDeliveryAttempts(ctx context.Context, deliveryID valueobject.ID) ([]*entities.WebhookDeliveryAttempt, error) {
+	return s.webhookRepo.ListWebhookDeliveryAttempts(ctx, deliveryID)
+}
+
+// ReplayWebhookDelivery only revives dead-lettered deliveries; pending ones
+// are already being retried and delivered ones must not be sent twice by
+// accident.
+func (s *AuthService) ReplayWebhookDelivery(ctx context.Context, deliveryID valueobject.ID) error {
+	return s.webhookRepo.ReplayWebhookDelivery(ctx, deliveryID)
+}
+
+// validateWebhookURL only accepts https URLs whose host resolves to public
+// addresses, so an admin cannot point signed requests at internal services.
+// The dispatcher checks the dialed address again, as DNS may change later.
+func validateWebhookURL(ctx context.Context, rawURL string) error {
+	if len(rawURL) > webhookMaxURLLength {
+		return appErrors.ErrInvalidWebhookURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return appErrors.ErrInvalidWebhookURL
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return appErrors.ErrInvalidWebhookURL
+	}
+
+	for _, addr := range addrs {
+		if !utils.IsPublicIP(addr) {
+			return appErrors.ErrInvalidWebhookURL
+		}
+	}
+
+	return nil
+}
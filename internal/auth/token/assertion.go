@@ -0,0 +1,63 @@
+package token
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+var assertionSigningMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodRS384.Alg(),
+	jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodPS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodES384.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+func VerifyClientAssertion(assertion string, publicKeyPEM string, clientID string, audience string) (*jwt.RegisteredClaims, error) {
+	publicKey, err := parsePublicKey([]byte(publicKeyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client public key: %w", err)
+	}
+
+	var claims jwt.RegisteredClaims
+	_, err = jwt.ParseWithClaims(assertion, &claims, func(*jwt.Token) (any, error) {
+		return publicKey, nil
+	},
+		jwt.WithValidMethods(assertionSigningMethods),
+		jwt.WithIssuer(clientID),
+		jwt.WithSubject(clientID),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, appErrors.ErrInvalidClient
+	}
+
+	if claims.ID == "" {
+		return nil, appErrors.ErrInvalidClient
+	}
+
+	return &claims, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("unsupported public key format")
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Key is a named secret used to sign access and refresh tokens.
+type Key struct {
+	ID     string
+	Secret string
+}
+
+// Keys holds the primary key, which signs every new token, and retiring keys
+// that are only accepted when verifying tokens issued before a rotation.
+//
+// To rotate APP_SECRET_KEY without logging anyone out:
+//  1. Add the current key to APP_RETIRING_SECRET_KEYS as "<APP_SECRET_KEY_ID>:<APP_SECRET_KEY>".
+//  2. Set APP_SECRET_KEY to the new secret and APP_SECRET_KEY_ID to a new ID, then deploy.
+//  3. Keep the old key in APP_RETIRING_SECRET_KEYS until the longest refresh
+//     token TTL of any client has passed since the deploy. A refresh token
+//     signed with it stays valid until then, even if unused for weeks, so a
+//     quiet auth_tokens_verified_total{key_status="retiring"} does not mean
+//     the key is unused. Then remove it and deploy again.
+//
+// Access tokens carry the key ID in their kid header. Refresh tokens and
+// tokens signed before key IDs existed are checked against every key,
+// primary first.
+type Keys struct {
+	Primary  Key
+	Retiring []Key
+}
+
+func NewKeys(primary Key, retiring []Key) (Keys, error) {
+	if primary.ID == "" || primary.Secret == "" {
+		return Keys{}, fmt.Errorf("primary secret key requires an id and a secret")
+	}
+
+	seen := map[string]struct{}{primary.ID: {}}
+	for _, key := range retiring {
+		if _, ok := seen[key.ID]; ok {
+			return Keys{}, fmt.Errorf("duplicate secret key id %q", key.ID)
+		}
+		seen[key.ID] = struct{}{}
+	}
+
+	return Keys{
+		Primary:  primary,
+		Retiring: retiring,
+	}, nil
+}
+
+// All returns the primary key followed by the retiring ones.
+func (k Keys) All() []Key {
+	return append([]Key{k.Primary}, k.Retiring...)
+}
+
+func (k Keys) Find(id string) (Key, bool) {
+	for _, key := range k.All() {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// IsPrimary reports whether id names the primary key.
+func (k Keys) IsPrimary(id string) bool {
+	return k.Primary.ID == id
+}
+
+// ParseKeys reads keys written as "id:secret".
+func ParseKeys(values []string) ([]Key, error) {
+	keys := make([]Key, 0, len(values))
+	for _, value := range values {
+		id, secret, found := strings.Cut(strings.TrimSpace(value), ":")
+		if !found || id == "" || secret == "" {
+			return nil, fmt.Errorf("invalid secret key %q, expected id:secret", id)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,120 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// Subject types tell account tokens from client credentials tokens.
+const (
+	SubjectTypeAccount = "account"
+	SubjectTypeClient  = "client"
+)
+
+type Claims struct {
+	SubjectType string `json:"sub_type"`
+	ClientID    string `json:"client_id,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	Actor       *Actor `json:"act,omitempty"`
+	// SessionID is the ID of the refresh token session the token was issued
+	// for, so logging out can revoke it.
+	SessionID string `json:"sid,omitempty"`
+	// KeyID is the ID of the key that verified the token. It is set by Parse
+	// and never serialized.
+	KeyID string `json:"-"`
+	jwt.RegisteredClaims
+}
+
+type Manager struct {
+	keys   Keys
+	issuer string
+}
+
+func NewManager(keys Keys, issuer string) *Manager {
+	return &Manager{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+func (m *Manager) Issuer() string {
+	return m.issuer
+}
+
+func (m *Manager) Generate(claims Claims, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.Issuer = m.issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t.Header["kid"] = m.keys.Primary.ID
+
+	signed, err := t.SignedString([]byte(m.keys.Primary.Secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return signed, nil
+}
+
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	candidates, err := m.candidateKeys(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range candidates {
+		var claims Claims
+		_, err = jwt.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (any, error) {
+			return []byte(key.Secret), nil
+		},
+			jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+			jwt.WithIssuer(m.issuer),
+			jwt.WithExpirationRequired(),
+		)
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				return nil, appErrors.ErrExpiredAccessToken
+			}
+			return nil, appErrors.ErrInvalidAccessToken
+		}
+
+		claims.KeyID = key.ID
+		return &claims, nil
+	}
+
+	return nil, appErrors.ErrInvalidAccessToken
+}
+
+// candidateKeys returns the key named by the kid header, or every key for
+// tokens signed before key IDs were added.
+func (m *Manager) candidateKeys(tokenString string) ([]Key, error) {
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	if err != nil {
+		return nil, appErrors.ErrInvalidAccessToken
+	}
+
+	kid, ok := unverified.Header["kid"].(string)
+	if !ok {
+		return m.keys.All(), nil
+	}
+
+	key, found := m.keys.Find(kid)
+	if !found {
+		return nil, appErrors.ErrInvalidAccessToken
+	}
+
+	return []Key{key}, nil
+}
@@ -0,0 +1,151 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+const testIssuer = "auth-service"
+
+var (
+	oldKey = Key{ID: "2025", Secret: "old-secret"}
+	newKey = Key{ID: "2026", Secret: "new-secret"}
+)
+
+func newTestManager(t *testing.T, primary Key, retiring ...Key) *Manager {
+	t.Helper()
+
+	keys, err := NewKeys(primary, retiring)
+	if err != nil {
+		t.Fatalf("NewKeys() error = %v", err)
+	}
+	return NewManager(keys, testIssuer)
+}
+
+// signWithoutKid signs claims the way tokens were signed before key IDs.
+func signWithoutKid(t *testing.T, key Key, ttl time.Duration) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := Claims{
+		SubjectType: SubjectTypeAccount,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "account",
+			Issuer:    testIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key.Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestManagerParseByKid(t *testing.T) {
+	rotated := newTestManager(t, newKey, oldKey)
+
+	fresh, err := rotated.Generate(Claims{SubjectType: SubjectTypeAccount}, time.Minute)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	issuedBefore, err := newTestManager(t, oldKey).Generate(Claims{SubjectType: SubjectTypeAccount}, time.Minute)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantKeyID string
+	}{
+		{name: "primary key", token: fresh, wantKeyID: newKey.ID},
+		{name: "retiring key", token: issuedBefore, wantKeyID: oldKey.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := rotated.Parse(tt.token)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if claims.KeyID != tt.wantKeyID {
+				t.Errorf("KeyID = %q, want %q", claims.KeyID, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+func TestManagerParseRejectsKidMismatch(t *testing.T) {
+	rotated := newTestManager(t, newKey, oldKey)
+
+	// Signed with the retiring key but naming the primary one: the kid picks
+	// the only key tried, so the signature does not verify.
+	mislabelled := newTestManager(t, Key{ID: newKey.ID, Secret: oldKey.Secret})
+	token, err := mislabelled.Generate(Claims{SubjectType: SubjectTypeAccount}, time.Minute)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err = rotated.Parse(token); !errors.Is(err, appErrors.ErrInvalidAccessToken) {
+		t.Errorf("Parse() error = %v, want %v", err, appErrors.ErrInvalidAccessToken)
+	}
+
+	// A kid naming a removed key is not retried against the other keys.
+	removed, err := newTestManager(t, Key{ID: "2024", Secret: newKey.Secret}).Generate(Claims{SubjectType: SubjectTypeAccount}, time.Minute)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err = rotated.Parse(removed); !errors.Is(err, appErrors.ErrInvalidAccessToken) {
+		t.Errorf("Parse() error = %v, want %v", err, appErrors.ErrInvalidAccessToken)
+	}
+}
+
+func TestManagerParseWithoutKid(t *testing.T) {
+	rotated := newTestManager(t, newKey, oldKey)
+
+	tests := []struct {
+		name      string
+		key       Key
+		ttl       time.Duration
+		wantKeyID string
+		wantErr   error
+	}{
+		{name: "primary key", key: newKey, ttl: time.Minute, wantKeyID: newKey.ID},
+		{name: "retiring key", key: oldKey, ttl: time.Minute, wantKeyID: oldKey.ID},
+		{name: "unknown key", key: Key{ID: "x", Secret: "unknown"}, ttl: time.Minute, wantErr: appErrors.ErrInvalidAccessToken},
+		{name: "expired", key: oldKey, ttl: -time.Minute, wantErr: appErrors.ErrExpiredAccessToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := rotated.Parse(signWithoutKid(t, tt.key, tt.ttl))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if claims.KeyID != tt.wantKeyID {
+				t.Errorf("KeyID = %q, want %q", claims.KeyID, tt.wantKeyID)
+			}
+		})
+	}
+}
+
+func TestNewKeysRejectsDuplicateIDs(t *testing.T) {
+	if _, err := NewKeys(newKey, []Key{oldKey, {ID: oldKey.ID, Secret: "other"}}); err == nil {
+		t.Error("NewKeys() accepted a duplicate retiring key ID")
+	}
+	if _, err := NewKeys(newKey, []Key{{ID: newKey.ID, Secret: "other"}}); err == nil {
+		t.Error("NewKeys() accepted a retiring key with the primary key's ID")
+	}
+}
@@ -0,0 +1,24 @@
+package valueobject
+
+type AuthEventType string
+
+const (
+	AuthEventTypeOtpSend            AuthEventType = "otp_send"
+	AuthEventTypeOtpVerify          AuthEventType = "otp_verify"
+	AuthEventTypeTokenRefresh       AuthEventType = "token_refresh"
+	AuthEventTypeLogout             AuthEventType = "logout"
+	AuthEventTypeClientCredentials  AuthEventType = "client_credentials"
+	AuthEventTypeAccountCreated     AuthEventType = "account_created"
+	AuthEventTypeAccountRoleChanged AuthEventType = "account_role_changed"
+	AuthEventTypeApiKeyCreated      AuthEventType = "api_key_created"
+	AuthEventTypeApiKeyRevoked      AuthEventType = "api_key_revoked"
+	AuthEventTypeImpersonation      AuthEventType = "impersonation"
+	AuthEventTypeTokensRevoked      AuthEventType = "tokens_revoked"
+)
+
+type AuthEventOutcome string
+
+const (
+	AuthEventOutcomeSuccess AuthEventOutcome = "success"
+	AuthEventOutcomeFailure AuthEventOutcome = "failure"
+)
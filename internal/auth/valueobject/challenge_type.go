@@ -0,0 +1,36 @@
+package valueobject
+
+import (
+	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
+)
+
+type ChallengeType string
+
+const (
+	ChallengeTypeCaptcha     ChallengeType = "captcha"
+	ChallengeTypeProofOfWork ChallengeType = "proof_of_work"
+)
+
+func (ct ChallengeType) ToProto() authv1.ChallengeType {
+	switch ct {
+	case ChallengeTypeCaptcha:
+		return authv1.ChallengeType_CAPTCHA
+	case ChallengeTypeProofOfWork:
+		return authv1.ChallengeType_PROOF_OF_WORK
+	default:
+		return authv1.ChallengeType_CHALLENGE_TYPE_UNSPECIFIED
+	}
+}
+
+// NewChallengeTypeFromProto maps an unset type to the empty ChallengeType,
+// since most requests carry no challenge at all.
+func NewChallengeTypeFromProto(protoChallengeType authv1.ChallengeType) ChallengeType {
+	switch protoChallengeType {
+	case authv1.ChallengeType_CAPTCHA:
+		return ChallengeTypeCaptcha
+	case authv1.ChallengeType_PROOF_OF_WORK:
+		return ChallengeTypeProofOfWork
+	default:
+		return ""
+	}
+}
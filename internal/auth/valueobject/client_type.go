@@ -0,0 +1,20 @@
+package valueobject
+
+import (
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+type ClientType string
+
+const (
+	ClientTypePublic       ClientType = "public"
+	ClientTypeConfidential ClientType = "confidential"
+)
+
+func (ct ClientType) Validate() error {
+	if ct != ClientTypePublic && ct != ClientTypeConfidential {
+		return appErrors.ErrInvalidClientType
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package valueobject
+
+import (
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+type GrantType string
+
+const (
+	GrantTypeOtp               GrantType = "otp"
+	GrantTypeRefreshToken      GrantType = "refresh_token"
+	GrantTypeClientCredentials GrantType = "client_credentials"
+)
+
+func (gt GrantType) Validate() error {
+	if gt != GrantTypeOtp && gt != GrantTypeRefreshToken && gt != GrantTypeClientCredentials {
+		return appErrors.ErrInvalidGrantType
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
 )
 
 type ID uuid.UUID
@@ -20,7 +21,7 @@ func NewID() (ID, error) {
 func NewIDFromString(data string) (ID, error) {
 	u, err := uuid.Parse(data)
 	if err != nil {
-		return ID(u), fmt.Errorf("invalid UUID format: %w", err)
+		return ID(u), fmt.Errorf("%w: %w", appErrors.ErrInvalidID, err)
 	}
 
 	return ID(u), nil
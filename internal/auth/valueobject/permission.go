@@ -0,0 +1,31 @@
+package valueobject
+
+import (
+	"regexp"
+
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+type Permission string
+
+const (
+	PermissionAccountsRead   Permission = "accounts:read"
+	PermissionAccountsWrite  Permission = "accounts:write"
+	PermissionImpersonate    Permission = "accounts:impersonate"
+	PermissionSessionsRevoke Permission = "sessions:revoke"
+	PermissionRolesRead      Permission = "roles:read"
+	PermissionRolesWrite     Permission = "roles:write"
+	PermissionAuditRead      Permission = "audit:read"
+	PermissionWebhooksRead   Permission = "webhooks:read"
+	PermissionWebhooksWrite  Permission = "webhooks:write"
+)
+
+var permissionPattern = regexp.MustCompile(`^[a-z_]+:[a-z_]+$`)
+
+func (p Permission) Validate() error {
+	if !permissionPattern.MatchString(string(p)) {
+		return appErrors.ErrInvalidPermission
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package valueobject
 
 import (
+	"regexp"
+
 	"github.com/teacinema-go/auth-service/internal/errors"
 	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
 )
@@ -12,18 +14,27 @@ const (
 	RoleAdmin Role = "admin"
 )
 
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
 func (r Role) Validate() error {
-	if r != RoleUser && r != RoleAdmin {
+	if !roleNamePattern.MatchString(string(r)) {
 		return errors.ErrInvalidRole
 	}
 
 	return nil
 }
 
+func (r Role) IsBuiltin() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
 func (r Role) ToProto() accountv1.Role {
-	if r == RoleUser {
+	switch r {
+	case RoleUser:
 		return accountv1.Role_USER
+	case RoleAdmin:
+		return accountv1.Role_ADMIN
+	default:
+		return accountv1.Role_ROLE_UNSPECIFIED
 	}
-
-	return accountv1.Role_ADMIN
 }
@@ -0,0 +1,21 @@
+package valueobject
+
+import (
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusDead      WebhookDeliveryStatus = "dead"
+)
+
+func (s WebhookDeliveryStatus) Validate() error {
+	if s != WebhookDeliveryStatusPending && s != WebhookDeliveryStatusDelivered && s != WebhookDeliveryStatusDead {
+		return appErrors.ErrInvalidDeliveryStatus
+	}
+
+	return nil
+}
@@ -3,22 +3,43 @@ package config
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
 	"github.com/teacinema-go/core/constants"
 )
 
 type Config struct {
-	App      App      `mapstructure:",squash"`
-	Postgres Postgres `mapstructure:",squash"`
-	Redis    Redis    `mapstructure:",squash"`
+	App             App             `mapstructure:",squash"`
+	Postgres        Postgres        `mapstructure:",squash"`
+	Redis           Redis           `mapstructure:",squash"`
+	Audit           Audit           `mapstructure:",squash"`
+	Outbox          Outbox          `mapstructure:",squash"`
+	Webhook         Webhook         `mapstructure:",squash"`
+	Tracing         Tracing         `mapstructure:",squash"`
+	Health          Health          `mapstructure:",squash"`
+	Gateway         Gateway         `mapstructure:",squash"`
+	TLS             TLS             `mapstructure:",squash"`
+	RateLimit       RateLimit       `mapstructure:",squash"`
+	Challenge       Challenge       `mapstructure:",squash"`
+	Phone           Phone           `mapstructure:",squash"`
+	Email           Email           `mapstructure:",squash"`
+	FieldEncryption FieldEncryption `mapstructure:",squash"`
+	Revocation      Revocation      `mapstructure:",squash"`
 }
 
 type App struct {
-	Env       constants.Env `mapstructure:"APP_ENV" validate:"required"`
-	Port      int           `mapstructure:"APP_PORT" validate:"required"`
-	SecretKey string        `mapstructure:"APP_SECRET_KEY" validate:"required"`
+	Env                constants.Env `mapstructure:"APP_ENV" validate:"required"`
+	Port               int           `mapstructure:"APP_PORT" validate:"required"`
+	MetricsPort        int           `mapstructure:"APP_METRICS_PORT" validate:"required,nefield=Port"`
+	ErrorCompat        bool          `mapstructure:"APP_ERROR_COMPAT"`
+	SecretKey          string        `mapstructure:"APP_SECRET_KEY" validate:"required"`
+	SecretKeyID        string        `mapstructure:"APP_SECRET_KEY_ID" validate:"required"`
+	RetiringSecretKeys []string      `mapstructure:"APP_RETIRING_SECRET_KEYS"`
+	Issuer             string        `mapstructure:"APP_ISSUER" validate:"required"`
 }
 
 type Postgres struct {
@@ -36,8 +57,201 @@ type Redis struct {
 	Password string `mapstructure:"REDIS_PASSWORD"`
 }
 
+// Audit events record phone numbers and emails as an HMAC keyed with
+// IdentifierHashKey.
+type Audit struct {
+	RetentionPeriod   time.Duration `mapstructure:"AUDIT_RETENTION_PERIOD" validate:"required"`
+	CleanupInterval   time.Duration `mapstructure:"AUDIT_CLEANUP_INTERVAL" validate:"required"`
+	IdentifierHashKey string        `mapstructure:"AUDIT_IDENTIFIER_HASH_KEY" validate:"required"`
+}
+
+type Outbox struct {
+	Publisher         string        `mapstructure:"OUTBOX_PUBLISHER" validate:"required,oneof=log file kafka nats"`
+	FilePath          string        `mapstructure:"OUTBOX_FILE_PATH" validate:"required_if=Publisher file"`
+	PollInterval      time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL" validate:"required"`
+	BatchSize         int32         `mapstructure:"OUTBOX_BATCH_SIZE" validate:"required,min=1"`
+	EventSource       string        `mapstructure:"OUTBOX_EVENT_SOURCE" validate:"required"`
+	KafkaBrokers      []string      `mapstructure:"KAFKA_BROKERS" validate:"required_if=Publisher kafka"`
+	KafkaTopic        string        `mapstructure:"KAFKA_TOPIC" validate:"required_if=Publisher kafka"`
+	NatsURL           string        `mapstructure:"NATS_URL" validate:"required_if=Publisher nats"`
+	NatsSubjectPrefix string        `mapstructure:"NATS_SUBJECT_PREFIX" validate:"required_if=Publisher nats"`
+}
+
+type Webhook struct {
+	PollInterval time.Duration `mapstructure:"WEBHOOK_POLL_INTERVAL" validate:"required"`
+	BatchSize    int32         `mapstructure:"WEBHOOK_BATCH_SIZE" validate:"required,min=1"`
+	MaxAttempts  int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS" validate:"required,min=1"`
+	Timeout      time.Duration `mapstructure:"WEBHOOK_TIMEOUT" validate:"required"`
+}
+
+type Tracing struct {
+	Exporter     string  `mapstructure:"TRACING_EXPORTER" validate:"required,oneof=none stdout otlp"`
+	ServiceName  string  `mapstructure:"TRACING_SERVICE_NAME" validate:"required"`
+	SampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO" validate:"min=0,max=1"`
+	OtlpEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT" validate:"required_if=Exporter otlp"`
+	OtlpInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"`
+}
+
+type Health struct {
+	CheckInterval time.Duration `mapstructure:"HEALTH_CHECK_INTERVAL" validate:"required"`
+	CheckTimeout  time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT" validate:"required"`
+	DrainDelay    time.Duration `mapstructure:"HEALTH_DRAIN_DELAY" validate:"min=0"`
+}
+
+type TLS struct {
+	Enabled        bool          `mapstructure:"TLS_ENABLED"`
+	CertFile       string        `mapstructure:"TLS_CERT_FILE" validate:"required_if=Enabled true"`
+	KeyFile        string        `mapstructure:"TLS_KEY_FILE" validate:"required_if=Enabled true"`
+	MinVersion     string        `mapstructure:"TLS_MIN_VERSION" validate:"oneof=1.2 1.3"`
+	CipherSuites   []string      `mapstructure:"TLS_CIPHER_SUITES"`
+	ClientCAFile   string        `mapstructure:"TLS_CLIENT_CA_FILE"`
+	ReloadInterval time.Duration `mapstructure:"TLS_RELOAD_INTERVAL" validate:"required"`
+}
+
+// RateLimit is opt-in. FailOpen lets requests through while Redis is
+// unreachable, trading abuse protection for availability; turn it off to
+// reject them with Unavailable instead.
+type RateLimit struct {
+	Enabled  bool     `mapstructure:"RATE_LIMIT_ENABLED"`
+	Rules    []string `mapstructure:"RATE_LIMIT_RULES"`
+	FailOpen bool     `mapstructure:"RATE_LIMIT_FAIL_OPEN"`
+}
+
+type Challenge struct {
+	Enabled          bool          `mapstructure:"CHALLENGE_ENABLED"`
+	CaptchaProvider  string        `mapstructure:"CHALLENGE_CAPTCHA_PROVIDER" validate:"oneof=none recaptcha hcaptcha turnstile"`
+	CaptchaSecret    string        `mapstructure:"CHALLENGE_CAPTCHA_SECRET" validate:"required_unless=CaptchaProvider none"`
+	CaptchaVerifyURL string        `mapstructure:"CHALLENGE_CAPTCHA_VERIFY_URL" validate:"omitempty,url"`
+	CaptchaMinScore  float64       `mapstructure:"CHALLENGE_CAPTCHA_MIN_SCORE" validate:"min=0,max=1"`
+	CaptchaTimeout   time.Duration `mapstructure:"CHALLENGE_CAPTCHA_TIMEOUT" validate:"required"`
+	PowDifficulty    int           `mapstructure:"CHALLENGE_POW_DIFFICULTY" validate:"min=0,max=32"`
+	PowSecret        string        `mapstructure:"CHALLENGE_POW_SECRET"`
+	PowTTL           time.Duration `mapstructure:"CHALLENGE_POW_TTL" validate:"required"`
+	HighRiskPrefixes []string      `mapstructure:"CHALLENGE_HIGH_RISK_PREFIXES"`
+	BurstLimit       int           `mapstructure:"CHALLENGE_BURST_LIMIT" validate:"min=0"`
+	BurstPeriod      time.Duration `mapstructure:"CHALLENGE_BURST_PERIOD" validate:"required"`
+	KnownIPTTL       time.Duration `mapstructure:"CHALLENGE_KNOWN_IP_TTL" validate:"required,max=168h"`
+}
+
+type Phone struct {
+	AllowedCountries []string `mapstructure:"PHONE_ALLOWED_COUNTRIES"`
+	DeniedCountries  []string `mapstructure:"PHONE_DENIED_COUNTRIES"`
+	BlockedTypes     []string `mapstructure:"PHONE_BLOCKED_TYPES"`
+}
+
+type Email struct {
+	ProviderRules         bool          `mapstructure:"EMAIL_PROVIDER_RULES"`
+	DisposableDomainsFile string        `mapstructure:"EMAIL_DISPOSABLE_DOMAINS_FILE"`
+	MXCheck               bool          `mapstructure:"EMAIL_MX_CHECK"`
+	MXTimeout             time.Duration `mapstructure:"EMAIL_MX_TIMEOUT" validate:"required"`
+}
+
+type FieldEncryption struct {
+	Enabled                bool     `mapstructure:"FIELD_ENCRYPTION_ENABLED"`
+	Keys                   []string `mapstructure:"FIELD_ENCRYPTION_KEYS" validate:"required_if=Enabled true"`
+	PrimaryKeyID           string   `mapstructure:"FIELD_ENCRYPTION_PRIMARY_KEY_ID" validate:"required_if=Enabled true"`
+	BlindIndexKey          string   `mapstructure:"FIELD_ENCRYPTION_BLIND_INDEX_KEY" validate:"required_if=Enabled true"`
+	RetiringBlindIndexKeys []string `mapstructure:"FIELD_ENCRYPTION_RETIRING_BLIND_INDEX_KEYS"`
+	BatchSize              int32    `mapstructure:"FIELD_ENCRYPTION_BATCH_SIZE" validate:"required"`
+}
+
+// Revocation entries live in Redis for the longest access token TTL of any
+// client. Access tokens are rejected with Unavailable while Redis is down,
+// since accepting them would let revoked ones through.
+type Revocation struct {
+	EventsEnabled bool `mapstructure:"REVOCATION_EVENTS_ENABLED"`
+}
+
+// Gateway only reads X-Forwarded-For from connections coming from the
+// TrustedProxies CIDRs, the proxies in front of it.
+type Gateway struct {
+	Port                 int           `mapstructure:"GATEWAY_PORT" validate:"required"`
+	TrustedProxies       []string      `mapstructure:"GATEWAY_TRUSTED_PROXIES" validate:"dive,cidr"`
+	CORSAllowedOrigins   []string      `mapstructure:"GATEWAY_CORS_ALLOWED_ORIGINS"`
+	CORSAllowedHeaders   []string      `mapstructure:"GATEWAY_CORS_ALLOWED_HEADERS"`
+	CORSAllowCredentials bool          `mapstructure:"GATEWAY_CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAge           time.Duration `mapstructure:"GATEWAY_CORS_MAX_AGE" validate:"min=0"`
+	SessionCookies       bool          `mapstructure:"GATEWAY_SESSION_COOKIES"`
+	CookieDomain         string        `mapstructure:"GATEWAY_COOKIE_DOMAIN"`
+	CookieSameSite       string        `mapstructure:"GATEWAY_COOKIE_SAME_SITE" validate:"oneof=strict lax none"`
+}
+
 func Load() (*Config, error) {
+	viper.SetDefault("APP_ISSUER", "auth-service")
+	viper.SetDefault("APP_METRICS_PORT", 9090)
+	viper.SetDefault("APP_ERROR_COMPAT", true)
+	viper.SetDefault("APP_SECRET_KEY_ID", "default")
+	viper.SetDefault("APP_RETIRING_SECRET_KEYS", []string{})
 	viper.SetDefault("POSTGRES_SSLMODE", "disable")
+	viper.SetDefault("AUDIT_RETENTION_PERIOD", "8760h")
+	viper.SetDefault("AUDIT_CLEANUP_INTERVAL", "1h")
+	viper.SetDefault("OUTBOX_PUBLISHER", "log")
+	viper.SetDefault("OUTBOX_FILE_PATH", "")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_EVENT_SOURCE", "auth-service")
+	viper.SetDefault("KAFKA_BROKERS", []string{})
+	viper.SetDefault("KAFKA_TOPIC", "auth.events")
+	viper.SetDefault("NATS_URL", "")
+	viper.SetDefault("NATS_SUBJECT_PREFIX", "auth.events")
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", "1s")
+	viper.SetDefault("WEBHOOK_BATCH_SIZE", 50)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 10)
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_SERVICE_NAME", "auth-service")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	viper.SetDefault("TRACING_OTLP_INSECURE", false)
+	viper.SetDefault("HEALTH_CHECK_INTERVAL", "5s")
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("HEALTH_DRAIN_DELAY", "0s")
+	viper.SetDefault("TLS_ENABLED", false)
+	viper.SetDefault("TLS_CERT_FILE", "")
+	viper.SetDefault("TLS_KEY_FILE", "")
+	viper.SetDefault("TLS_MIN_VERSION", "1.2")
+	viper.SetDefault("TLS_CIPHER_SUITES", []string{})
+	viper.SetDefault("TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("TLS_RELOAD_INTERVAL", "30s")
+	viper.SetDefault("RATE_LIMIT_ENABLED", false)
+	viper.SetDefault("RATE_LIMIT_RULES", []string{"SendOtp:ip:10/1m", "VerifyOtp:ip:30/1m", "Refresh:ip:60/1m"})
+	viper.SetDefault("RATE_LIMIT_FAIL_OPEN", true)
+	viper.SetDefault("CHALLENGE_ENABLED", false)
+	viper.SetDefault("CHALLENGE_CAPTCHA_PROVIDER", "none")
+	viper.SetDefault("CHALLENGE_CAPTCHA_SECRET", "")
+	viper.SetDefault("CHALLENGE_CAPTCHA_VERIFY_URL", "")
+	viper.SetDefault("CHALLENGE_CAPTCHA_MIN_SCORE", 0.5)
+	viper.SetDefault("CHALLENGE_CAPTCHA_TIMEOUT", "5s")
+	viper.SetDefault("CHALLENGE_POW_DIFFICULTY", 20)
+	viper.SetDefault("CHALLENGE_POW_SECRET", "")
+	viper.SetDefault("CHALLENGE_POW_TTL", "5m")
+	viper.SetDefault("CHALLENGE_HIGH_RISK_PREFIXES", []string{})
+	viper.SetDefault("CHALLENGE_BURST_LIMIT", 5)
+	viper.SetDefault("CHALLENGE_BURST_PERIOD", "1m")
+	viper.SetDefault("CHALLENGE_KNOWN_IP_TTL", "24h")
+	viper.SetDefault("PHONE_ALLOWED_COUNTRIES", []string{})
+	viper.SetDefault("PHONE_DENIED_COUNTRIES", []string{})
+	viper.SetDefault("PHONE_BLOCKED_TYPES", []string{"premium_rate", "toll_free", "voip"})
+	viper.SetDefault("EMAIL_PROVIDER_RULES", false)
+	viper.SetDefault("EMAIL_DISPOSABLE_DOMAINS_FILE", "")
+	viper.SetDefault("EMAIL_MX_CHECK", false)
+	viper.SetDefault("EMAIL_MX_TIMEOUT", "2s")
+	viper.SetDefault("FIELD_ENCRYPTION_ENABLED", false)
+	viper.SetDefault("FIELD_ENCRYPTION_KEYS", []string{})
+	viper.SetDefault("FIELD_ENCRYPTION_PRIMARY_KEY_ID", "")
+	viper.SetDefault("FIELD_ENCRYPTION_BLIND_INDEX_KEY", "")
+	viper.SetDefault("FIELD_ENCRYPTION_RETIRING_BLIND_INDEX_KEYS", []string{})
+	viper.SetDefault("FIELD_ENCRYPTION_BATCH_SIZE", 500)
+	viper.SetDefault("REVOCATION_EVENTS_ENABLED", false)
+	viper.SetDefault("GATEWAY_PORT", 8080)
+	viper.SetDefault("GATEWAY_TRUSTED_PROXIES", []string{})
+	viper.SetDefault("GATEWAY_CORS_ALLOWED_ORIGINS", []string{})
+	viper.SetDefault("GATEWAY_CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "X-Client-Id", "X-CSRF-Token"})
+	viper.SetDefault("GATEWAY_CORS_ALLOW_CREDENTIALS", false)
+	viper.SetDefault("GATEWAY_CORS_MAX_AGE", "10m")
+	viper.SetDefault("GATEWAY_SESSION_COOKIES", false)
+	viper.SetDefault("GATEWAY_COOKIE_DOMAIN", "")
+	viper.SetDefault("GATEWAY_COOKIE_SAME_SITE", "strict")
 
 	viper.SetConfigFile(".env")
 	viper.AutomaticEnv()
@@ -55,9 +269,64 @@ func Load() (*Config, error) {
 	}
 
 	validate := validator.New()
+	validate.RegisterStructValidation(validateGateway, Gateway{})
+	validate.RegisterStructValidation(validateChallenge, Challenge{})
 	if err := validate.Struct(&cfg); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	if err := validatePowSecret(&cfg); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
 	return &cfg, nil
 }
+
+// validatePowSecret keeps the proof of work secret apart from the token
+// signing keys. A leaked signing key must not also let an attacker mint
+// proof of work challenges, and the other way round.
+func validatePowSecret(cfg *Config) error {
+	if cfg.Challenge.PowSecret == "" {
+		return nil
+	}
+
+	retiring, err := token.ParseKeys(cfg.App.RetiringSecretKeys)
+	if err != nil {
+		return fmt.Errorf("APP_RETIRING_SECRET_KEYS: %w", err)
+	}
+
+	if cfg.Challenge.PowSecret == cfg.App.SecretKey || slices.ContainsFunc(retiring, func(key token.Key) bool {
+		return key.Secret == cfg.Challenge.PowSecret
+	}) {
+		return errors.New("CHALLENGE_POW_SECRET must differ from the token signing keys")
+	}
+
+	return nil
+}
+
+// validateChallenge requires an enabled gate to have a challenge clients can
+// solve, and a signing key for proof of work challenges when they can be
+// issued.
+func validateChallenge(sl validator.StructLevel) {
+	challenge := sl.Current().Interface().(Challenge)
+	if !challenge.Enabled {
+		return
+	}
+
+	if challenge.CaptchaProvider == "none" && challenge.PowDifficulty == 0 {
+		sl.ReportError(challenge.CaptchaProvider, "CaptchaProvider", "CaptchaProvider", "captcha_or_pow", "")
+	}
+	if challenge.PowDifficulty > 0 && challenge.PowSecret == "" {
+		sl.ReportError(challenge.PowSecret, "PowSecret", "PowSecret", "required_with_pow", "")
+	}
+}
+
+// validateGateway rejects a wildcard origin combined with credentials.
+// Browsers refuse that pair, and reflecting every origin instead would let
+// any site make credentialed calls.
+func validateGateway(sl validator.StructLevel) {
+	gateway := sl.Current().Interface().(Gateway)
+	if gateway.CORSAllowCredentials && slices.Contains(gateway.CORSAllowedOrigins, "*") {
+		sl.ReportError(gateway.CORSAllowedOrigins, "CORSAllowedOrigins", "CORSAllowedOrigins", "no_wildcard_with_credentials", "")
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestValidateGateway(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterStructValidation(validateGateway, Gateway{})
+
+	valid := Gateway{
+		Port:                 8080,
+		TrustedProxies:       []string{"10.0.0.0/8", "fd00::/8"},
+		CORSAllowedOrigins:   []string{"https://app.example.com"},
+		CORSAllowCredentials: true,
+		CookieSameSite:       "strict",
+	}
+	if err := validate.Struct(valid); err != nil {
+		t.Fatalf("valid gateway config rejected: %v", err)
+	}
+
+	wildcard := valid
+	wildcard.CORSAllowedOrigins = []string{"*"}
+	if err := validate.Struct(wildcard); err == nil {
+		t.Error("wildcard origin with credentials accepted")
+	}
+
+	wildcard.CORSAllowCredentials = false
+	if err := validate.Struct(wildcard); err != nil {
+		t.Errorf("wildcard origin without credentials rejected: %v", err)
+	}
+
+	badProxy := valid
+	badProxy.TrustedProxies = []string{"10.0.0.1"}
+	if err := validate.Struct(badProxy); err == nil {
+		t.Error("trusted proxy without prefix length accepted")
+	}
+}
+
+func TestValidateChallenge(t *testing.T) {
+	validate := validator.New()
+	validate.RegisterStructValidation(validateChallenge, Challenge{})
+
+	valid := Challenge{
+		Enabled:         true,
+		CaptchaProvider: "none",
+		CaptchaTimeout:  time.Second,
+		PowDifficulty:   20,
+		PowSecret:       "pow-secret",
+		PowTTL:          time.Minute,
+		BurstPeriod:     time.Minute,
+		KnownIPTTL:      24 * time.Hour,
+	}
+	if err := validate.Struct(valid); err != nil {
+		t.Fatalf("valid challenge config rejected: %v", err)
+	}
+
+	noSecret := valid
+	noSecret.PowSecret = ""
+	if err := validate.Struct(noSecret); err == nil {
+		t.Error("proof of work without a secret accepted")
+	}
+
+	captchaOnly := noSecret
+	captchaOnly.PowDifficulty = 0
+	captchaOnly.CaptchaProvider = "turnstile"
+	captchaOnly.CaptchaSecret = "captcha-secret"
+	if err := validate.Struct(captchaOnly); err != nil {
+		t.Errorf("captcha-only config rejected: %v", err)
+	}
+
+	unsolvable := noSecret
+	unsolvable.PowDifficulty = 0
+	if err := validate.Struct(unsolvable); err == nil {
+		t.Error("enabled gate without captcha or proof of work accepted")
+	}
+
+	unsolvable.Enabled = false
+	if err := validate.Struct(unsolvable); err != nil {
+		t.Errorf("disabled gate rejected: %v", err)
+	}
+
+	longTTL := valid
+	longTTL.KnownIPTTL = 720 * time.Hour
+	if err := validate.Struct(longTTL); err == nil {
+		t.Error("known IP TTL over a week accepted")
+	}
+}
+
+func TestValidatePowSecret(t *testing.T) {
+	tests := []struct {
+		name      string
+		powSecret string
+		retiring  []string
+		wantErr   bool
+	}{
+		{name: "own secret", powSecret: "pow-secret", retiring: []string{"2025:old-secret"}},
+		{name: "no proof of work", powSecret: ""},
+		{name: "primary key", powSecret: "signing-secret", wantErr: true},
+		{name: "retiring key", powSecret: "old-secret", retiring: []string{"2025:old-secret"}, wantErr: true},
+		{name: "malformed retiring key", powSecret: "pow-secret", retiring: []string{"old-secret"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				App:       App{SecretKey: "signing-secret", SecretKeyID: "2026", RetiringSecretKeys: tt.retiring},
+				Challenge: Challenge{PowSecret: tt.powSecret},
+			}
+			if err := validatePowSecret(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("validatePowSecret() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -6,12 +6,50 @@ import (
 
 var (
 	ErrNotFound              = errors.New("not found")
+	ErrInvalidID             = errors.New("invalid id")
 	ErrInvalidEmail          = errors.New("invalid email")
 	ErrInvalidE164Phone      = errors.New("invalid e.164 phone number")
 	ErrInvalidIdentifierType = errors.New("invalid identifier type")
+	ErrInvalidOtp            = errors.New("invalid otp")
+	ErrExpiredOtp            = errors.New("expired otp")
 	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrExpiredRefreshToken   = errors.New("expired refresh token")
+	ErrInvalidAccessToken    = errors.New("invalid access token")
+	ErrExpiredAccessToken    = errors.New("expired access token")
+	ErrRevokedAccessToken    = errors.New("revoked access token")
+	ErrRevocationUnavailable = errors.New("revocation list unavailable")
 	ErrRefreshTokenNotFound  = errors.New("refresh token not found")
 	ErrAccountNotFound       = errors.New("account not found")
 	ErrAccountAlreadyExists  = errors.New("account already exists")
 	ErrInvalidRole           = errors.New("invalid role")
+	ErrRoleNotFound          = errors.New("role not found")
+	ErrRoleAlreadyExists     = errors.New("role already exists")
+	ErrRoleInUse             = errors.New("role is assigned to accounts")
+	ErrRoleLockout           = errors.New("change would remove roles:write from the caller or its last holder")
+	ErrBuiltinRole           = errors.New("builtin role cannot be deleted")
+	ErrInvalidPermission     = errors.New("invalid permission")
+	ErrInvalidClientType     = errors.New("invalid client type")
+	ErrInvalidGrantType      = errors.New("invalid grant type")
+	ErrClientNotFound        = errors.New("client not found")
+	ErrGrantTypeNotAllowed   = errors.New("grant type not allowed for client")
+	ErrInvalidClient         = errors.New("invalid client credentials")
+	ErrInvalidScope          = errors.New("invalid scope")
+	ErrInvalidApiKey         = errors.New("invalid api key")
+	ErrInvalidApiKeyName     = errors.New("invalid api key name")
+	ErrInvalidApiKeyExpiry   = errors.New("invalid api key expiry")
+	ErrApiKeyNotFound        = errors.New("api key not found")
+	ErrInvalidReason         = errors.New("invalid reason")
+	ErrInvalidImpersonation  = errors.New("invalid impersonation target")
+	ErrInvalidPageToken      = errors.New("invalid page token")
+	ErrInvalidTimeRange      = errors.New("invalid time range")
+	ErrInvalidWebhookURL     = errors.New("invalid webhook url")
+	ErrInvalidEventType      = errors.New("invalid event type")
+	ErrInvalidDeliveryStatus = errors.New("invalid delivery status")
+	ErrWebhookNotFound       = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrRateLimited           = errors.New("rate limit exceeded")
+	ErrChallengeRequired     = errors.New("challenge required")
+	ErrInvalidChallenge      = errors.New("invalid challenge response")
+	ErrPhoneNotAllowed       = errors.New("phone number not allowed")
+	ErrEmailNotAllowed       = errors.New("email address not allowed")
 )
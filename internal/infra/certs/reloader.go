@@ -0,0 +1,190 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/core/logger"
+)
+
+var minVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader serves the certificate, key and client CA bundle currently on
+// disk. Files are polled and swapped in atomically when they change, so
+// rotated certificates take effect on the next handshake without a restart.
+type Reloader struct {
+	cfg          *config.TLS
+	minVersion   uint16
+	cipherSuites []uint16
+	current      atomic.Pointer[tls.Config]
+	stamps       map[string]fileStamp
+}
+
+func NewReloader(cfg *config.TLS) (*Reloader, error) {
+	minVersion, ok := minVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tls min version %q", cfg.MinVersion)
+	}
+
+	cipherSuites, err := parseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader{
+		cfg:          cfg,
+		minVersion:   minVersion,
+		cipherSuites: cipherSuites,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ServerConfig returns the config to hand to the listener. Each handshake
+// picks up the latest loaded certificates.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}
+}
+
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+
+		if err := r.load(); err != nil {
+			logger.Error("failed to reload tls certificates, keeping previous ones", "error", err)
+			continue
+		}
+		logger.Info("tls certificates reloaded")
+	}
+}
+
+func (r *Reloader) load() error {
+	stamps, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   r.minVersion,
+		CipherSuites: r.cipherSuites,
+		NextProtos:   []string{"h2"},
+	}
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client ca bundle: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client ca bundle contains no certificates")
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.current.Store(tlsConfig)
+	r.stamps = stamps
+
+	return nil
+}
+
+func (r *Reloader) changed() bool {
+	stamps, err := r.stat()
+	if err != nil {
+		logger.Warn("failed to stat tls files", "error", err)
+		return false
+	}
+
+	for path, stamp := range stamps {
+		if r.stamps[path] != stamp {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Reloader) stat() (map[string]fileStamp, error) {
+	paths := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		paths = append(paths, r.cfg.ClientCAFile)
+	}
+
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return stamps, nil
+}
+
+// parseCipherSuites accepts the standard names of the secure suites only.
+// The list applies to TLS 1.2; TLS 1.3 suites are not configurable in Go.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
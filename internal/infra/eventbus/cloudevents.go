@@ -0,0 +1,49 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsContentType = "application/cloudevents+json"
+	cloudEventsTypePrefix  = "com.teacinema.auth."
+)
+
+// cloudEvent is the structured-mode CloudEvents 1.0 JSON envelope. The
+// aggregate ID doubles as the partition key so brokers keep each account's
+// events in order.
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	PartitionKey    string          `json:"partitionkey"`
+	Data            json.RawMessage `json:"data"`
+}
+
+func marshalCloudEvent(source string, event *entities.OutboxEvent) ([]byte, error) {
+	data, err := json.Marshal(cloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              event.ID.ToString(),
+		Source:          source,
+		Type:            cloudEventsTypePrefix + event.EventType,
+		Subject:         event.AggregateType + "/" + event.AggregateID.ToString(),
+		Time:            event.CreatedAt.UTC(),
+		DataContentType: "application/json",
+		PartitionKey:    event.AggregateID.ToString(),
+		Data:            event.Payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cloud event: %w", err)
+	}
+
+	return data, nil
+}
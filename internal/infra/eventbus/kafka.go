@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/config"
+)
+
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type KafkaPublisher struct {
+	writer kafkaWriter
+	source string
+}
+
+func NewKafkaPublisher(cfg *config.Outbox) *KafkaPublisher {
+	return newKafkaPublisher(&kafka.Writer{
+		Addr:         kafka.TCP(cfg.KafkaBrokers...),
+		Topic:        cfg.KafkaTopic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: kafkaBatchTimeout,
+	}, cfg.EventSource)
+}
+
+func newKafkaPublisher(writer kafkaWriter, source string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: writer,
+		source: source,
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	value, err := marshalCloudEvent(p.source, event)
+	if err != nil {
+		return err
+	}
+
+	err = p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.AggregateID.ToString()),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte(cloudEventsContentType)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write kafka message: %w", err)
+	}
+
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,102 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type fakeKafkaWriter struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (w *fakeKafkaWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeKafkaWriter) Close() error {
+	return nil
+}
+
+func newTestEvent(t *testing.T) *entities.OutboxEvent {
+	t.Helper()
+
+	eventID, err := valueobject.NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountID, err := valueobject.NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &entities.OutboxEvent{
+		ID:            eventID,
+		AggregateType: "account",
+		AggregateID:   accountID,
+		EventType:     "account.created",
+		Payload:       json.RawMessage(`{"account_id":"` + accountID.ToString() + `"}`),
+		CreatedAt:     time.Date(2026, 2, 14, 9, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestKafkaPublisherPublish(t *testing.T) {
+	writer := &fakeKafkaWriter{}
+	publisher := newKafkaPublisher(writer, "auth-service")
+	event := newTestEvent(t)
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if len(writer.messages) != 1 {
+		t.Fatalf("wrote %d messages, want 1", len(writer.messages))
+	}
+	msg := writer.messages[0]
+
+	if got := string(msg.Key); got != event.AggregateID.ToString() {
+		t.Errorf("key = %q, want the aggregate ID %q", got, event.AggregateID.ToString())
+	}
+
+	if len(msg.Headers) != 1 || msg.Headers[0].Key != "content-type" || string(msg.Headers[0].Value) != cloudEventsContentType {
+		t.Errorf("headers = %v, want content-type %q", msg.Headers, cloudEventsContentType)
+	}
+
+	var ce cloudEvent
+	if err := json.Unmarshal(msg.Value, &ce); err != nil {
+		t.Fatalf("value is not a cloud event: %v", err)
+	}
+	if ce.ID != event.ID.ToString() {
+		t.Errorf("id = %q, want %q", ce.ID, event.ID.ToString())
+	}
+	if ce.Type != cloudEventsTypePrefix+event.EventType {
+		t.Errorf("type = %q, want %q", ce.Type, cloudEventsTypePrefix+event.EventType)
+	}
+	if ce.PartitionKey != event.AggregateID.ToString() {
+		t.Errorf("partitionkey = %q, want %q", ce.PartitionKey, event.AggregateID.ToString())
+	}
+	if string(ce.Data) != string(event.Payload) {
+		t.Errorf("data = %s, want %s", ce.Data, event.Payload)
+	}
+}
+
+func TestKafkaPublisherPublishError(t *testing.T) {
+	writeErr := errors.New("broker unavailable")
+	publisher := newKafkaPublisher(&fakeKafkaWriter{err: writeErr}, "auth-service")
+
+	err := publisher.Publish(context.Background(), newTestEvent(t))
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("Publish() error = %v, want %v", err, writeErr)
+	}
+}
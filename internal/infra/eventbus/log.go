@@ -0,0 +1,30 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/core/logger"
+)
+
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event *entities.OutboxEvent) error {
+	logger.Info("event published",
+		"event_id", event.ID.ToString(),
+		"event_type", event.EventType,
+		"aggregate_type", event.AggregateType,
+		"aggregate_id", event.AggregateID.ToString(),
+		"payload", string(event.Payload),
+	)
+
+	return nil
+}
+
+func (p *LogPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event *entities.OutboxEvent) error
+	Close() error
+}
+
+// MultiPublisher hands every event to all publishers. If any of them fails
+// the relay retries the event on all of them, so each publisher must
+// tolerate duplicates.
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p *MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/config"
+)
+
+// NatsPublisher publishes to JetStream on <prefix>.<aggregate>.<id>.<type>,
+// so consumers can filter per account. The event ID is sent as the message
+// ID, letting the stream drop redeliveries from the at-least-once relay.
+type NatsPublisher struct {
+	conn          *nats.Conn
+	js            jetstream.JetStream
+	subjectPrefix string
+	source        string
+}
+
+func NewNatsPublisher(cfg *config.Outbox) (*NatsPublisher, error) {
+	conn, err := nats.Connect(cfg.NatsURL, nats.Name(cfg.EventSource))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	publisher, err := newNatsPublisher(conn, cfg.NatsSubjectPrefix, cfg.EventSource)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return publisher, nil
+}
+
+func newNatsPublisher(conn *nats.Conn, subjectPrefix string, source string) (*NatsPublisher, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	return &NatsPublisher{
+		conn:          conn,
+		js:            js,
+		subjectPrefix: subjectPrefix,
+		source:        source,
+	}, nil
+}
+
+func (p *NatsPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	data, err := marshalCloudEvent(p.source, event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(fmt.Sprintf("%s.%s.%s.%s", p.subjectPrefix, event.AggregateType, event.AggregateID.ToString(), event.EventType))
+	msg.Data = data
+	msg.Header.Set("Content-Type", cloudEventsContentType)
+
+	if _, err = p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID.ToString())); err != nil {
+		return fmt.Errorf("failed to publish to jetstream: %w", err)
+	}
+
+	return nil
+}
+
+func (p *NatsPublisher) Close() error {
+	return p.conn.Drain()
+}
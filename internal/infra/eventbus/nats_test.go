@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func runNatsServer(t *testing.T) *nats.Conn {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go srv.Start()
+	t.Cleanup(srv.Shutdown)
+
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+
+	return conn
+}
+
+func TestNatsPublisherPublish(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn := runNatsServer(t)
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:       "AUTH",
+		Subjects:   []string{"auth.>"},
+		Duplicates: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publisher, err := newNatsPublisher(conn, "auth", "auth-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := newTestEvent(t)
+	// The relay delivers at least once; the redelivery must be dropped.
+	for range 2 {
+		if err = publisher.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 1 {
+		t.Fatalf("stream holds %d messages, want 1", info.State.Msgs)
+	}
+
+	msg, err := stream.GetLastMsgForSubject(ctx, "auth.>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSubject := "auth.account." + event.AggregateID.ToString() + ".account.created"
+	if msg.Subject != wantSubject {
+		t.Errorf("subject = %q, want %q", msg.Subject, wantSubject)
+	}
+	if got := msg.Header.Get(jetstream.MsgIDHeader); got != event.ID.ToString() {
+		t.Errorf("%s = %q, want %q", jetstream.MsgIDHeader, got, event.ID.ToString())
+	}
+	if got := msg.Header.Get("Content-Type"); got != cloudEventsContentType {
+		t.Errorf("Content-Type = %q, want %q", got, cloudEventsContentType)
+	}
+
+	var ce cloudEvent
+	if err = json.Unmarshal(msg.Data, &ce); err != nil {
+		t.Fatalf("data is not a cloud event: %v", err)
+	}
+	if ce.ID != event.ID.ToString() {
+		t.Errorf("id = %q, want %q", ce.ID, event.ID.ToString())
+	}
+}
@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type WebhookDeliveryStore interface {
+	ListWebhookSubscriptionsForEvent(ctx context.Context, eventType string) ([]*entities.WebhookSubscription, error)
+	CreateWebhookDelivery(ctx context.Context, arg dto.CreateWebhookDeliveryParams) error
+}
+
+// WebhookPublisher fans an event out into one pending delivery per matching
+// subscription. Deliveries are unique per subscription and event, so a relay
+// retry does not queue the same event twice.
+type WebhookPublisher struct {
+	store  WebhookDeliveryStore
+	source string
+}
+
+func NewWebhookPublisher(store WebhookDeliveryStore, source string) *WebhookPublisher {
+	return &WebhookPublisher{
+		store:  store,
+		source: source,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event *entities.OutboxEvent) error {
+	subscriptions, err := p.store.ListWebhookSubscriptionsForEvent(ctx, event.EventType)
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := marshalCloudEvent(p.source, event)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		deliveryID, err := valueobject.NewID()
+		if err != nil {
+			return err
+		}
+
+		err = p.store.CreateWebhookDelivery(ctx, dto.CreateWebhookDeliveryParams{
+			ID:             deliveryID,
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			Payload:        payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p *WebhookPublisher) Close() error {
+	return nil
+}
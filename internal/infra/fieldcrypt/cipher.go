@@ -0,0 +1,262 @@
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// versionField values are bound to their column only. They are still
+	// decrypted, and re-encrypted as versionRow by encrypt-accounts.
+	versionField byte = 1
+	versionRow   byte = 2
+	keySize           = 32
+	maxKeyIDLen       = 255
+)
+
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Cipher encrypts column values with envelope encryption. Every value gets a
+// fresh data key; the data key is sealed with the primary key-encryption key
+// and stored next to the ciphertext together with that key's ID, so older
+// keys stay usable for decryption after the primary changes.
+//
+// Layout: version | len(keyID) | keyID | sealed data key | nonce | ciphertext.
+//
+// Blind indexes are keyed separately. Retiring index keys keep lookups of
+// rows indexed under them working until encrypt-accounts has re-indexed
+// those rows under the primary index key.
+type Cipher struct {
+	primaryKeyID string
+	keys         map[string]cipher.AEAD
+	indexKeys    []indexKey
+}
+
+type indexKey struct {
+	id  string
+	key []byte
+}
+
+// NewCipher takes keys written as "id:base64key", each 32 bytes once
+// decoded, and separate 32-byte base64 keys for blind indexes: the one new
+// indexes are written with and any still being retired.
+func NewCipher(keys []string, primaryKeyID string, indexKey string, retiringIndexKeys []string) (*Cipher, error) {
+	c := &Cipher{
+		primaryKeyID: primaryKeyID,
+		keys:         make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for _, value := range keys {
+		id, encoded, found := strings.Cut(strings.TrimSpace(value), ":")
+		if !found || id == "" || len(id) > maxKeyIDLen {
+			return nil, fmt.Errorf("invalid encryption key %q, expected id:base64key", id)
+		}
+
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[id] = aead
+	}
+
+	if _, ok := c.keys[primaryKeyID]; !ok {
+		return nil, fmt.Errorf("primary encryption key %q is not configured", primaryKeyID)
+	}
+
+	for _, encoded := range append([]string{indexKey}, retiringIndexKeys...) {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blind index key: %w", err)
+		}
+		c.indexKeys = append(c.indexKeys, newIndexKey(key))
+	}
+
+	return c, nil
+}
+
+func (c *Cipher) PrimaryKeyID() string {
+	return c.primaryKeyID
+}
+
+// IndexKeyID identifies the primary blind index key. It is derived from the
+// key, so rows can record which key indexed them without configuring IDs.
+func (c *Cipher) IndexKeyID() string {
+	return c.indexKeys[0].id
+}
+
+// Encrypt seals plaintext under the primary key. field and rowID are bound
+// to the ciphertext as additional data, so a value cannot be moved to
+// another column or another row.
+func (c *Cipher) Encrypt(field string, rowID string, plaintext string) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	sealedKey, err := seal(c.keys[c.primaryKeyID], dataKey, []byte(c.primaryKeyID))
+	if err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := seal(dataAEAD, []byte(plaintext), additionalData(versionRow, field, rowID))
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 2+len(c.primaryKeyID)+len(sealedKey)+len(ciphertext))
+	out = append(out, versionRow, byte(len(c.primaryKeyID)))
+	out = append(out, c.primaryKeyID...)
+	out = append(out, sealedKey...)
+	out = append(out, ciphertext...)
+
+	return out, nil
+}
+
+// Decrypt opens a value sealed by Encrypt for the same field and rowID.
+func (c *Cipher) Decrypt(field string, rowID string, data []byte) (string, error) {
+	version, keyID, rest, err := splitHeader(data)
+	if err != nil {
+		return "", err
+	}
+
+	kek, ok := c.keys[keyID]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+
+	sealedKeyLen := kek.NonceSize() + keySize + kek.Overhead()
+	if len(rest) < sealedKeyLen {
+		return "", errors.New("encrypted value is truncated")
+	}
+
+	dataKey, err := open(kek, rest[:sealedKeyLen], []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataAEAD, rest[sealedKeyLen:], additionalData(version, field, rowID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a deterministic keyed hash of value under the primary
+// index key, used to look rows up by an encrypted column without decrypting
+// it.
+func (c *Cipher) BlindIndex(field string, value string) []byte {
+	return c.indexKeys[0].sum(field, value)
+}
+
+// BlindIndexes returns the index of value under every index key, primary
+// first, for lookups that must also find rows not yet re-indexed.
+func (c *Cipher) BlindIndexes(field string, value string) [][]byte {
+	indexes := make([][]byte, 0, len(c.indexKeys))
+	for _, k := range c.indexKeys {
+		indexes = append(indexes, k.sum(field, value))
+	}
+
+	return indexes
+}
+
+func newIndexKey(key []byte) indexKey {
+	id := sha256.Sum256(key)
+	return indexKey{
+		id:  hex.EncodeToString(id[:8]),
+		key: key,
+	}
+}
+
+func (k indexKey) sum(field string, value string) []byte {
+	mac := hmac.New(sha256.New, k.key)
+	mac.Write([]byte(field + ":" + value))
+	return mac.Sum(nil)
+}
+
+func additionalData(version byte, field string, rowID string) []byte {
+	if version == versionField {
+		return []byte(field)
+	}
+
+	return []byte(field + "\x00" + rowID)
+}
+
+func splitHeader(data []byte) (byte, string, []byte, error) {
+	if len(data) < 2 || (data[0] != versionField && data[0] != versionRow) {
+		return 0, "", nil, errors.New("unsupported encrypted value")
+	}
+
+	keyIDLen := int(data[1])
+	if len(data) < 2+keyIDLen {
+		return 0, "", nil, errors.New("encrypted value is truncated")
+	}
+
+	return data[0], string(data[2 : 2+keyIDLen]), data[2+keyIDLen:], nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, data []byte, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("encrypted value is truncated")
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,198 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func newTestKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestCipher(t *testing.T, keys []string, primaryKeyID string, indexKey string, retiringIndexKeys ...string) *Cipher {
+	t.Helper()
+
+	c, err := NewCipher(keys, primaryKeyID, indexKey, retiringIndexKeys)
+	if err != nil {
+		t.Fatalf("NewCipher() error = %v", err)
+	}
+	return c
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	c := newTestCipher(t, []string{"k1:" + newTestKey(t)}, "k1", newTestKey(t))
+
+	encrypted, err := c.Encrypt("email", "row-1", "someone@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Contains(encrypted, []byte("someone@example.com")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+
+	got, err := c.Decrypt("email", "row-1", encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if got != "someone@example.com" {
+		t.Errorf("Decrypt() = %q, want %q", got, "someone@example.com")
+	}
+
+	again, err := c.Encrypt("email", "row-1", "someone@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Equal(encrypted, again) {
+		t.Error("encrypting the same value twice gave the same ciphertext")
+	}
+}
+
+func TestCipherRejectsTampering(t *testing.T) {
+	c := newTestCipher(t, []string{"k1:" + newTestKey(t)}, "k1", newTestKey(t))
+
+	encrypted, err := c.Encrypt("phone", "row-1", "+16502530000")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	header := 2 + len("k1")
+	for _, i := range []int{header, header + 30, len(encrypted) - 1} {
+		tampered := bytes.Clone(encrypted)
+		tampered[i] ^= 0x01
+		if _, err = c.Decrypt("phone", "row-1", tampered); err == nil {
+			t.Errorf("Decrypt() accepted a value with byte %d flipped", i)
+		}
+	}
+
+	if _, err = c.Decrypt("phone", "row-1", encrypted[:len(encrypted)-1]); err == nil {
+		t.Error("Decrypt() accepted a truncated value")
+	}
+	if _, err = c.Decrypt("email", "row-1", encrypted); err == nil {
+		t.Error("Decrypt() accepted a value moved to another field")
+	}
+	if _, err = c.Decrypt("phone", "row-2", encrypted); err == nil {
+		t.Error("Decrypt() accepted a value moved to another row")
+	}
+}
+
+func TestCipherKeyRotation(t *testing.T) {
+	oldKey, newKey, indexKey := newTestKey(t), newTestKey(t), newTestKey(t)
+	old := newTestCipher(t, []string{"k1:" + oldKey}, "k1", indexKey)
+
+	encrypted, err := old.Encrypt("email", "row-1", "someone@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	rotated := newTestCipher(t, []string{"k1:" + oldKey, "k2:" + newKey}, "k2", indexKey)
+	if got, err := rotated.Decrypt("email", "row-1", encrypted); err != nil || got != "someone@example.com" {
+		t.Errorf("Decrypt() with the retired key = %q, %v", got, err)
+	}
+
+	withoutOld := newTestCipher(t, []string{"k2:" + newKey}, "k2", indexKey)
+	if _, err = withoutOld.Decrypt("email", "row-1", encrypted); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt() error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+// Values written before rows were bound to their ID are only bound to the
+// field, and must stay readable.
+func TestCipherDecryptsFieldBoundValues(t *testing.T) {
+	c := newTestCipher(t, []string{"k1:" + newTestKey(t)}, "k1", newTestKey(t))
+
+	encrypted, err := c.Encrypt("email", "row-1", "someone@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	// Re-seal the payload the way the first envelope version did.
+	_, keyID, rest, err := splitHeader(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kek := c.keys[keyID]
+	sealedKeyLen := kek.NonceSize() + keySize + kek.Overhead()
+	dataKey, err := open(kek, rest[:sealedKeyLen], []byte(keyID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := seal(dataAEAD, []byte("someone@example.com"), []byte("email"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := append([]byte{versionField, byte(len(keyID))}, keyID...)
+	v1 = append(v1, rest[:sealedKeyLen]...)
+	v1 = append(v1, ciphertext...)
+
+	got, err := c.Decrypt("email", "any-row", v1)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if got != "someone@example.com" {
+		t.Errorf("Decrypt() = %q, want %q", got, "someone@example.com")
+	}
+}
+
+func TestCipherBlindIndexRotation(t *testing.T) {
+	keys := []string{"k1:" + newTestKey(t)}
+	oldIndexKey, newIndexKey := newTestKey(t), newTestKey(t)
+
+	old := newTestCipher(t, keys, "k1", oldIndexKey)
+	rotated := newTestCipher(t, keys, "k1", newIndexKey, oldIndexKey)
+
+	if old.IndexKeyID() == rotated.IndexKeyID() {
+		t.Fatal("index key IDs do not change with the key")
+	}
+	if !bytes.Equal(old.BlindIndex("email", "a@example.com"), old.BlindIndex("email", "a@example.com")) {
+		t.Fatal("BlindIndex() is not deterministic")
+	}
+	if bytes.Equal(old.BlindIndex("email", "a@example.com"), old.BlindIndex("phone", "a@example.com")) {
+		t.Error("BlindIndex() is the same for different fields")
+	}
+
+	indexes := rotated.BlindIndexes("email", "a@example.com")
+	if len(indexes) != 2 {
+		t.Fatalf("BlindIndexes() returned %d indexes, want 2", len(indexes))
+	}
+	if !bytes.Equal(indexes[0], rotated.BlindIndex("email", "a@example.com")) {
+		t.Error("BlindIndexes() does not start with the primary index")
+	}
+	if !bytes.Equal(indexes[1], old.BlindIndex("email", "a@example.com")) {
+		t.Error("BlindIndexes() does not include the retiring key's index")
+	}
+}
+
+func TestNewCipherRejectsInvalidKeys(t *testing.T) {
+	key := newTestKey(t)
+	tests := map[string]struct {
+		keys         []string
+		primaryKeyID string
+		indexKey     string
+		retiring     []string
+	}{
+		"missing primary":        {keys: []string{"k1:" + key}, primaryKeyID: "k2", indexKey: key},
+		"short key":              {keys: []string{"k1:c2hvcnQ="}, primaryKeyID: "k1", indexKey: key},
+		"no key id":              {keys: []string{key}, primaryKeyID: "k1", indexKey: key},
+		"bad index key":          {keys: []string{"k1:" + key}, primaryKeyID: "k1", indexKey: "c2hvcnQ="},
+		"bad retiring index key": {keys: []string{"k1:" + key}, primaryKeyID: "k1", indexKey: key, retiring: []string{"nope"}},
+	}
+
+	for name, tt := range tests {
+		if _, err := NewCipher(tt.keys, tt.primaryKeyID, tt.indexKey, tt.retiring); err == nil {
+			t.Errorf("%s: NewCipher() accepted invalid keys", name)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/teacinema-go/auth-service/internal/infra/tracing"
+	"github.com/teacinema-go/core/logger"
+)
+
+type loggerKey struct{}
+
+func NewContext(ctx context.Context, log *logger.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext returns the request-scoped logger set by the Logging
+// interceptor, or a logger carrying only the trace fields of ctx.
+func FromContext(ctx context.Context) *logger.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*logger.Logger); ok {
+		return log
+	}
+
+	return logger.With(tracing.LogFields(ctx)...)
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+const namespace = "auth"
+
+const (
+	otpResultSent     = "sent"
+	otpResultVerified = "verified"
+	otpResultFailed   = "failed"
+	otpResultExpired  = "expired"
+)
+
+const (
+	keyStatusPrimary  = "primary"
+	keyStatusRetiring = "retiring"
+)
+
+type Metrics struct {
+	registry       *prometheus.Registry
+	rpcRequests    *prometheus.CounterVec
+	rpcDuration    *prometheus.HistogramVec
+	otp            *prometheus.CounterVec
+	tokensIssued   *prometheus.CounterVec
+	tokensRotated  prometheus.Counter
+	tokensRevoked  *prometheus.CounterVec
+	tokensVerified *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "Handled gRPC requests by method, status code and response error code.",
+		}, []string{"method", "code", "error_code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "gRPC request latency by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		otp: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "otp",
+			Name:      "total",
+			Help:      "OTP codes by identifier type and result.",
+		}, []string{"identifier_type", "result"}),
+		tokensIssued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tokens",
+			Name:      "issued_total",
+			Help:      "Issued access tokens by grant type.",
+		}, []string{"grant_type"}),
+		tokensRotated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tokens",
+			Name:      "rotated_total",
+			Help:      "Rotated refresh tokens.",
+		}),
+		tokensRevoked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tokens",
+			Name:      "revoked_total",
+			Help:      "Token revocations by scope: a session on logout, every token of an account, or a single access token.",
+		}, []string{"scope"}),
+		tokensVerified: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "tokens",
+			Name:      "verified_total",
+			Help:      "Verified tokens by token type and the secret key that verified them.",
+		}, []string{"token_type", "key_id", "key_status"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcRequests,
+		m.rpcDuration,
+		m.otp,
+		m.tokensIssued,
+		m.tokensRotated,
+		m.tokensRevoked,
+		m.tokensVerified,
+	)
+
+	return m
+}
+
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRPC(method string, code string, errorCode string, duration time.Duration) {
+	m.rpcRequests.WithLabelValues(method, code, errorCode).Inc()
+	m.rpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func (m *Metrics) OtpSent(identifierType valueobject.IdentifierType) {
+	m.otp.WithLabelValues(string(identifierType), otpResultSent).Inc()
+}
+
+func (m *Metrics) OtpVerified(identifierType valueobject.IdentifierType) {
+	m.otp.WithLabelValues(string(identifierType), otpResultVerified).Inc()
+}
+
+func (m *Metrics) OtpFailed(identifierType valueobject.IdentifierType) {
+	m.otp.WithLabelValues(string(identifierType), otpResultFailed).Inc()
+}
+
+func (m *Metrics) OtpExpired(identifierType valueobject.IdentifierType) {
+	m.otp.WithLabelValues(string(identifierType), otpResultExpired).Inc()
+}
+
+func (m *Metrics) TokenIssued(grantType valueobject.GrantType) {
+	m.tokensIssued.WithLabelValues(string(grantType)).Inc()
+}
+
+func (m *Metrics) TokenRotated() {
+	m.tokensRotated.Inc()
+}
+
+func (m *Metrics) TokenRevoked(scope string) {
+	m.tokensRevoked.WithLabelValues(scope).Inc()
+}
+
+func (m *Metrics) TokenVerified(tokenType string, keyID string, primary bool) {
+	status := keyStatusRetiring
+	if primary {
+		status = keyStatusPrimary
+	}
+	m.tokensVerified.WithLabelValues(tokenType, keyID, status).Inc()
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE clients
+    ADD COLUMN secret_hash VARCHAR(64),
+    ADD COLUMN public_key TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE clients
+    DROP COLUMN IF EXISTS public_key,
+    DROP COLUMN IF EXISTS secret_hash;
+-- +goose StatementEnd
@@ -0,0 +1,72 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Regular customer account'),
+    ('admin', 'Full administrative access');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('accounts:read', 'Read any account'),
+    ('accounts:write', 'Modify any account'),
+    ('sessions:revoke', 'Revoke sessions of any account'),
+    ('roles:read', 'Read roles and permissions'),
+    ('roles:write', 'Manage roles, permissions and role assignments');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM accounts
+ON CONFLICT (name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE accounts
+    ADD CONSTRAINT fk_accounts_role FOREIGN KEY (role) REFERENCES roles(name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts
+    DROP CONSTRAINT IF EXISTS fk_accounts_role;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS roles;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE impersonations (
+    id UUID PRIMARY KEY,
+    actor_account_id UUID NOT NULL REFERENCES accounts(id),
+    target_account_id UUID NOT NULL REFERENCES accounts(id),
+    client_id VARCHAR(64),
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_impersonations_target_account_id ON impersonations(target_account_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('accounts:impersonate', 'Issue impersonation tokens for other accounts');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'accounts:impersonate');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'accounts:impersonate';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS impersonations;
+-- +goose StatementEnd
@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE auth_events (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    account_id UUID,
+    identifier_hash VARCHAR(64),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    client_id VARCHAR(64),
+    outcome VARCHAR(20) NOT NULL,
+    error_code VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_auth_events_account_id_created_at ON auth_events(account_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_auth_events_created_at ON auth_events(created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION prevent_auth_events_update()
+RETURNS TRIGGER AS $$
+BEGIN
+   RAISE EXCEPTION 'auth_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER auth_events_append_only
+    BEFORE UPDATE ON auth_events
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_auth_events_update();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO permissions (name, description) VALUES
+    ('audit:read', 'Read the security audit log');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'audit:read');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'audit:read';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS auth_events_append_only ON auth_events;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS prevent_auth_events_update();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS auth_events;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- This version used to lowercase stored emails in SQL. Emails are now
+-- rewritten by the canonicalize-emails command, which keeps the originals
+-- in account_email_originals (20260224093012), and the lowercase check is
+-- added by 20260224094507. The version stays so databases that applied it
+-- keep a matching history.
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
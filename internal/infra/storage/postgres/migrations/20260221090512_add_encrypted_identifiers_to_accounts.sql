@@ -0,0 +1,40 @@
+-- +goose Up
+-- phone and email stay readable until the encrypt-accounts command has moved
+-- every row to the encrypted columns; it clears them as it goes.
+-- +goose StatementBegin
+ALTER TABLE accounts
+    ADD COLUMN phone_encrypted BYTEA,
+    ADD COLUMN phone_index BYTEA,
+    ADD COLUMN email_encrypted BYTEA,
+    ADD COLUMN email_index BYTEA,
+    ADD COLUMN encryption_key_id VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_accounts_phone_index ON accounts(phone_index);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_accounts_email_index ON accounts(email_index);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Dropping the encrypted columns would lose the only copy of those
+-- identifiers.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM accounts WHERE phone_encrypted IS NOT NULL OR email_encrypted IS NOT NULL) THEN
+        RAISE EXCEPTION 'accounts has encrypted identifiers, which rolling back would lose';
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS phone_encrypted,
+    DROP COLUMN IF EXISTS phone_index,
+    DROP COLUMN IF EXISTS email_encrypted,
+    DROP COLUMN IF EXISTS email_index,
+    DROP COLUMN IF EXISTS encryption_key_id;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- secret stays readable until the encrypt-accounts command has moved every
+-- subscription to secret_encrypted; it clears it as it goes.
+-- +goose StatementBegin
+ALTER TABLE webhook_subscriptions
+    ALTER COLUMN secret DROP NOT NULL,
+    ADD COLUMN secret_encrypted BYTEA,
+    ADD COLUMN encryption_key_id VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Dropping secret_encrypted would lose the only copy of those secrets.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM webhook_subscriptions WHERE secret IS NULL) THEN
+        RAISE EXCEPTION 'webhook_subscriptions has encrypted secrets; recreate those subscriptions before rolling back';
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE webhook_subscriptions
+    DROP COLUMN IF EXISTS secret_encrypted,
+    DROP COLUMN IF EXISTS encryption_key_id,
+    ALTER COLUMN secret SET NOT NULL;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Records which blind index key indexed a row, so encrypt-accounts can find
+-- rows to re-index after the key changes. Rows encrypted before this column
+-- existed are re-encrypted as well, which also binds them to their account.
+-- +goose StatementBegin
+ALTER TABLE accounts ADD COLUMN index_key_id VARCHAR(16);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts DROP COLUMN IF EXISTS index_key_id;
+-- +goose StatementEnd
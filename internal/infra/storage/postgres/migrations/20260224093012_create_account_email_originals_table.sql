@@ -0,0 +1,25 @@
+-- +goose Up
+-- Stored emails are canonicalized by the canonicalize-emails command, which
+-- uses the same function as the service and cannot be expressed in SQL. It
+-- keeps the address it replaces here so the change can be rolled back.
+-- +goose StatementBegin
+CREATE TABLE account_email_originals (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Rows that have been encrypted since keep their canonical address.
+-- +goose StatementBegin
+UPDATE accounts a
+SET email = o.email
+FROM account_email_originals o
+WHERE a.id = o.account_id
+  AND a.email IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_email_originals;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Stored emails must be lowercase, which makes the unique constraint on
+-- accounts.email case-insensitive. The check is added NOT VALID: new rows
+-- are checked right away, existing ones once canonicalize-emails has
+-- rewritten them and validated it. Until then, updating a row whose email
+-- is not lowercase fails, so run the command right after migrating.
+-- Databases that applied the first version of 20260219103610 already have
+-- the check.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'accounts_email_lowercase' AND conrelid = 'accounts'::regclass
+    ) THEN
+        ALTER TABLE accounts ADD CONSTRAINT accounts_email_lowercase CHECK (email = lower(email)) NOT VALID;
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_email_lowercase;
+-- +goose StatementEnd
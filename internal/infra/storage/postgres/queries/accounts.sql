@@ -1,27 +1,91 @@
+-- name: LockAccountIdentifier :exec
+-- Serializes account creation for one identifier until the transaction ends.
+SELECT pg_advisory_xact_lock(sqlc.arg(key)::bigint);
+
 -- name: CreateAccount :one
-INSERT INTO accounts (id, phone, email, role)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (phone, email) DO NOTHING
+-- Creates the account unless the identifier is taken, whether stored in
+-- plaintext or indexed under any blind index key. Run LockAccountIdentifier
+-- first in the same transaction, so concurrent sign-ups cannot both pass.
+INSERT INTO accounts (id, phone, email, role, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id)
+SELECT sqlc.arg(id), sqlc.narg(phone), sqlc.narg(email), sqlc.arg(role), sqlc.narg(phone_encrypted), sqlc.narg(phone_index), sqlc.narg(email_encrypted), sqlc.narg(email_index), sqlc.narg(encryption_key_id), sqlc.narg(index_key_id)
+WHERE NOT EXISTS (
+    SELECT 1 FROM accounts
+    WHERE phone = sqlc.narg(lookup_phone)
+       OR email = sqlc.narg(lookup_email)
+       OR phone_index = ANY(sqlc.arg(phone_indexes)::bytea[])
+       OR email_index = ANY(sqlc.arg(email_indexes)::bytea[])
+)
+ON CONFLICT DO NOTHING
 RETURNING id;
 
 -- name: AccountExistsByPhone :one
 SELECT EXISTS (
-    SELECT 1 FROM accounts WHERE phone = $1
+    SELECT 1 FROM accounts WHERE phone_index = ANY(sqlc.arg(phone_indexes)::bytea[]) OR phone = sqlc.narg(phone)
 ) AS exists;
 
 -- name: AccountExistsByEmail :one
 SELECT EXISTS (
-    SELECT 1 FROM accounts WHERE email = $1
+    SELECT 1 FROM accounts WHERE email_index = ANY(sqlc.arg(email_indexes)::bytea[]) OR email = sqlc.narg(email)
 ) AS exists;
 
 -- name: GetAccountByPhone :one
 SELECT * FROM accounts
-WHERE phone = $1 LIMIT 1;
+WHERE phone_index = ANY(sqlc.arg(phone_indexes)::bytea[]) OR phone = sqlc.narg(phone)
+LIMIT 1;
 
 -- name: GetAccountByEmail :one
 SELECT * FROM accounts
-WHERE email = $1 LIMIT 1;
+WHERE email_index = ANY(sqlc.arg(email_indexes)::bytea[]) OR email = sqlc.narg(email)
+LIMIT 1;
 
 -- name: GetAccountByID :one
 SELECT * FROM accounts
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateAccountRole :execrows
+UPDATE accounts
+SET role = $2
+WHERE id = $1;
+
+-- name: ListAccountsToEncrypt :many
+SELECT * FROM accounts
+WHERE id > sqlc.arg(after_id)
+  AND (phone IS NOT NULL
+    OR email IS NOT NULL
+    OR encryption_key_id <> sqlc.arg(primary_key_id)::text
+    OR index_key_id IS DISTINCT FROM sqlc.arg(index_key_id)::text)
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateAccountEncryptedIdentifiers :execrows
+UPDATE accounts
+SET phone = NULL,
+    email = NULL,
+    phone_encrypted = $2,
+    phone_index = $3,
+    email_encrypted = $4,
+    email_index = $5,
+    encryption_key_id = $6,
+    index_key_id = $7
+WHERE id = $1;
+
+-- name: ListAccountEmails :many
+SELECT id, email FROM accounts
+WHERE id > sqlc.arg(after_id)
+  AND email IS NOT NULL
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: CanonicalizeAccountEmail :execrows
+-- Records the address being replaced in account_email_originals. Nothing is
+-- changed when the stored address is no longer the one that was read.
+WITH original AS (
+    INSERT INTO account_email_originals (account_id, email)
+    SELECT id, email FROM accounts
+    WHERE id = sqlc.arg(id) AND email = sqlc.arg(original)
+    ON CONFLICT (account_id) DO NOTHING
+)
+UPDATE accounts
+SET email = sqlc.arg(canonical)
+WHERE id = sqlc.arg(id)
+  AND email = sqlc.arg(original);
@@ -0,0 +1,25 @@
+-- name: CreateApiKey :exec
+INSERT INTO api_keys (id, account_id, name, prefix, key_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetActiveApiKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+LIMIT 1;
+
+-- name: ListApiKeysByAccountID :many
+SELECT * FROM api_keys
+WHERE account_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND account_id = $2 AND revoked_at IS NULL;
+
+-- name: UpdateApiKeyLastUsedAt :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1;
\ No newline at end of file
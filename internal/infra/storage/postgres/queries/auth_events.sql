@@ -0,0 +1,23 @@
+-- name: CreateAuthEvent :exec
+INSERT INTO auth_events (id, event_type, account_id, identifier_hash, ip, user_agent, client_id, outcome, error_code)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListAuthEvents :many
+SELECT * FROM auth_events
+WHERE (sqlc.narg(account_id)::uuid IS NULL OR account_id = sqlc.narg(account_id)::uuid)
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time)::timestamptz)
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time)::timestamptz)
+  AND (
+    sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: DeleteAuthEventsBefore :execrows
+DELETE FROM auth_events
+WHERE id IN (
+    SELECT id FROM auth_events
+    WHERE created_at < sqlc.arg(before)
+    LIMIT sqlc.arg(batch_size)
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- name: GetClientByID :one
+SELECT * FROM clients
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLongestAccessTokenTTL :one
+-- Returns 0 when no client sets its own access token TTL.
+SELECT COALESCE(MAX(access_token_ttl_seconds), 0)::integer AS ttl_seconds FROM clients;
\ No newline at end of file
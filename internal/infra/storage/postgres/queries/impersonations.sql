@@ -0,0 +1,3 @@
+-- name: CreateImpersonation :exec
+INSERT INTO impersonations (id, actor_account_id, target_account_id, client_id, reason, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6);
\ No newline at end of file
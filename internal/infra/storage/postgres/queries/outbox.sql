@@ -0,0 +1,32 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (id, aggregate_type, aggregate_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ClaimOutboxEvents :many
+-- Only the oldest unpublished event of an aggregate can be claimed, so an
+-- event waiting for its retry holds back the later ones of its aggregate.
+UPDATE outbox
+SET next_attempt_at = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT e.id FROM outbox e
+    WHERE e.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+          SELECT 1 FROM outbox earlier
+          WHERE earlier.aggregate_id = e.aggregate_id
+            AND (earlier.created_at, earlier.id) < (e.created_at, e.id)
+      )
+    ORDER BY e.created_at, e.id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE OF e SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeleteOutboxEvent :exec
+DELETE FROM outbox WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    next_attempt_at = $2,
+    last_error = $3
+WHERE id = $1;
@@ -1,6 +1,6 @@
 -- name: CreateRefreshToken :exec
-INSERT INTO refresh_tokens (id, account_id, token_hash, expires_at)
-VALUES ($1,$2,$3,$4);
+INSERT INTO refresh_tokens (id, account_id, client_id, token_hash, expires_at)
+VALUES ($1,$2,$3,$4,$5);
 
 -- name: GetRefreshTokenByHash :one
 SELECT * FROM refresh_tokens
@@ -10,6 +10,10 @@ WHERE token_hash = $1 AND expires_at > NOW();
 DELETE FROM refresh_tokens
 WHERE token_hash = $1;
 
+-- name: DeleteRefreshTokenByHashAndClientID :execrows
+DELETE FROM refresh_tokens
+WHERE token_hash = $1 AND client_id = $2;
+
 -- name: DeleteRefreshTokensByAccountID :exec
 DELETE FROM refresh_tokens
 WHERE account_id = $1;
\ No newline at end of file
@@ -0,0 +1,43 @@
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: RoleExists :one
+SELECT EXISTS (
+    SELECT 1 FROM roles WHERE name = $1
+) AS exists;
+
+-- name: CreateRole :execrows
+INSERT INTO roles (name, description)
+VALUES ($1, $2)
+ON CONFLICT (name) DO NOTHING;
+
+-- name: DeleteRole :execrows
+DELETE FROM roles
+WHERE name = $1;
+
+-- name: ListPermissions :many
+SELECT * FROM permissions
+ORDER BY name;
+
+-- name: ListRolePermissions :many
+SELECT * FROM role_permissions
+ORDER BY role, permission;
+
+-- name: ListPermissionsByRole :many
+SELECT permission FROM role_permissions
+WHERE role = $1
+ORDER BY permission;
+
+-- name: AddRolePermission :exec
+INSERT INTO role_permissions (role, permission)
+VALUES ($1, $2);
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM role_permissions
+WHERE role = $1;
+
+-- name: CountAccountsWithPermission :one
+SELECT COUNT(*) FROM accounts a
+JOIN role_permissions rp ON rp.role = a.role
+WHERE rp.permission = $1;
\ No newline at end of file
@@ -0,0 +1,91 @@
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (id, url, event_types, secret, secret_encrypted, encryption_key_id)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetWebhookSubscriptionByID :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+ORDER BY created_at;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+SELECT * FROM webhook_subscriptions
+WHERE cardinality(event_types) = 0 OR sqlc.arg(event_type)::text = ANY(event_types);
+
+-- name: ListWebhookSubscriptionsToEncrypt :many
+SELECT * FROM webhook_subscriptions
+WHERE id > sqlc.arg(after_id)
+  AND (secret IS NOT NULL
+    OR encryption_key_id <> sqlc.arg(primary_key_id)::text)
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateWebhookSubscriptionEncryptedSecret :execrows
+UPDATE webhook_subscriptions
+SET secret = NULL,
+    secret_encrypted = $2,
+    encryption_key_id = $3
+WHERE id = $1;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(locked_until)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = NULL,
+    delivered_at = NOW()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    next_attempt_at = $3,
+    last_status_code = $4,
+    last_error = $5
+WHERE id = $1;
+
+-- name: ReplayWebhookDelivery :execrows
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = NOW()
+WHERE id = $1 AND status = 'dead';
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: CreateWebhookDeliveryAttempt :exec
+INSERT INTO webhook_delivery_attempts (id, delivery_id, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY created_at;
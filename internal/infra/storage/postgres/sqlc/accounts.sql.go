@@ -13,12 +13,17 @@ import (
 
 const accountExistsByEmail = `-- name: AccountExistsByEmail :one
 SELECT EXISTS (
-    SELECT 1 FROM accounts WHERE email = $1
+    SELECT 1 FROM accounts WHERE email_index = ANY($1::bytea[]) OR email = $2
 ) AS exists
 `
 
-func (q *Queries) AccountExistsByEmail(ctx context.Context, email *string) (bool, error) {
-	row := q.db.QueryRow(ctx, accountExistsByEmail, email)
+type AccountExistsByEmailParams struct {
+	EmailIndexes [][]byte `json:"email_indexes"`
+	Email        *string  `json:"email"`
+}
+
+func (q *Queries) AccountExistsByEmail(ctx context.Context, arg AccountExistsByEmailParams) (bool, error) {
+	row := q.db.QueryRow(ctx, accountExistsByEmail, arg.EmailIndexes, arg.Email)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
@@ -26,37 +31,101 @@ func (q *Queries) AccountExistsByEmail(ctx context.Context, email *string) (bool
 
 const accountExistsByPhone = `-- name: AccountExistsByPhone :one
 SELECT EXISTS (
-    SELECT 1 FROM accounts WHERE phone = $1
+    SELECT 1 FROM accounts WHERE phone_index = ANY($1::bytea[]) OR phone = $2
 ) AS exists
 `
 
-func (q *Queries) AccountExistsByPhone(ctx context.Context, phone *string) (bool, error) {
-	row := q.db.QueryRow(ctx, accountExistsByPhone, phone)
+type AccountExistsByPhoneParams struct {
+	PhoneIndexes [][]byte `json:"phone_indexes"`
+	Phone        *string  `json:"phone"`
+}
+
+func (q *Queries) AccountExistsByPhone(ctx context.Context, arg AccountExistsByPhoneParams) (bool, error) {
+	row := q.db.QueryRow(ctx, accountExistsByPhone, arg.PhoneIndexes, arg.Phone)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
+const canonicalizeAccountEmail = `-- name: CanonicalizeAccountEmail :execrows
+WITH original AS (
+    INSERT INTO account_email_originals (account_id, email)
+    SELECT id, email FROM accounts
+    WHERE id = $1 AND email = $2
+    ON CONFLICT (account_id) DO NOTHING
+)
+UPDATE accounts
+SET email = $3
+WHERE id = $1
+  AND email = $2
+`
+
+type CanonicalizeAccountEmailParams struct {
+	ID        uuid.UUID `json:"id"`
+	Original  *string   `json:"original"`
+	Canonical *string   `json:"canonical"`
+}
+
+// Records the address being replaced in account_email_originals. Nothing is
+// changed when the stored address is no longer the one that was read.
+func (q *Queries) CanonicalizeAccountEmail(ctx context.Context, arg CanonicalizeAccountEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, canonicalizeAccountEmail, arg.ID, arg.Original, arg.Canonical)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (id, phone, email, role)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (phone, email) DO NOTHING
+INSERT INTO accounts (id, phone, email, role, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id)
+SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+WHERE NOT EXISTS (
+    SELECT 1 FROM accounts
+    WHERE phone = $11
+       OR email = $12
+       OR phone_index = ANY($13::bytea[])
+       OR email_index = ANY($14::bytea[])
+)
+ON CONFLICT DO NOTHING
 RETURNING id
 `
 
 type CreateAccountParams struct {
-	ID    uuid.UUID `json:"id"`
-	Phone *string   `json:"phone"`
-	Email *string   `json:"email"`
-	Role  string    `json:"role"`
+	ID              uuid.UUID `json:"id"`
+	Phone           *string   `json:"phone"`
+	Email           *string   `json:"email"`
+	Role            string    `json:"role"`
+	PhoneEncrypted  []byte    `json:"phone_encrypted"`
+	PhoneIndex      []byte    `json:"phone_index"`
+	EmailEncrypted  []byte    `json:"email_encrypted"`
+	EmailIndex      []byte    `json:"email_index"`
+	EncryptionKeyID *string   `json:"encryption_key_id"`
+	IndexKeyID      *string   `json:"index_key_id"`
+	LookupPhone     *string   `json:"lookup_phone"`
+	LookupEmail     *string   `json:"lookup_email"`
+	PhoneIndexes    [][]byte  `json:"phone_indexes"`
+	EmailIndexes    [][]byte  `json:"email_indexes"`
 }
 
+// Creates the account unless the identifier is taken, whether stored in
+// plaintext or indexed under any blind index key. Run LockAccountIdentifier
+// first in the same transaction, so concurrent sign-ups cannot both pass.
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (uuid.UUID, error) {
 	row := q.db.QueryRow(ctx, createAccount,
 		arg.ID,
 		arg.Phone,
 		arg.Email,
 		arg.Role,
+		arg.PhoneEncrypted,
+		arg.PhoneIndex,
+		arg.EmailEncrypted,
+		arg.EmailIndex,
+		arg.EncryptionKeyID,
+		arg.IndexKeyID,
+		arg.LookupPhone,
+		arg.LookupEmail,
+		arg.PhoneIndexes,
+		arg.EmailIndexes,
 	)
 	var id uuid.UUID
 	err := row.Scan(&id)
@@ -64,12 +133,18 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (u
 }
 
 const getAccountByEmail = `-- name: GetAccountByEmail :one
-SELECT id, phone, email, role, created_at, updated_at FROM accounts
-WHERE email = $1 LIMIT 1
+SELECT id, phone, email, role, created_at, updated_at, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id FROM accounts
+WHERE email_index = ANY($1::bytea[]) OR email = $2
+LIMIT 1
 `
 
-func (q *Queries) GetAccountByEmail(ctx context.Context, email *string) (Account, error) {
-	row := q.db.QueryRow(ctx, getAccountByEmail, email)
+type GetAccountByEmailParams struct {
+	EmailIndexes [][]byte `json:"email_indexes"`
+	Email        *string  `json:"email"`
+}
+
+func (q *Queries) GetAccountByEmail(ctx context.Context, arg GetAccountByEmailParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByEmail, arg.EmailIndexes, arg.Email)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -78,12 +153,18 @@ func (q *Queries) GetAccountByEmail(ctx context.Context, email *string) (Account
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PhoneEncrypted,
+		&i.PhoneIndex,
+		&i.EmailEncrypted,
+		&i.EmailIndex,
+		&i.EncryptionKeyID,
+		&i.IndexKeyID,
 	)
 	return i, err
 }
 
 const getAccountByID = `-- name: GetAccountByID :one
-SELECT id, phone, email, role, created_at, updated_at FROM accounts
+SELECT id, phone, email, role, created_at, updated_at, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -97,17 +178,29 @@ func (q *Queries) GetAccountByID(ctx context.Context, id uuid.UUID) (Account, er
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PhoneEncrypted,
+		&i.PhoneIndex,
+		&i.EmailEncrypted,
+		&i.EmailIndex,
+		&i.EncryptionKeyID,
+		&i.IndexKeyID,
 	)
 	return i, err
 }
 
 const getAccountByPhone = `-- name: GetAccountByPhone :one
-SELECT id, phone, email, role, created_at, updated_at FROM accounts
-WHERE phone = $1 LIMIT 1
+SELECT id, phone, email, role, created_at, updated_at, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id FROM accounts
+WHERE phone_index = ANY($1::bytea[]) OR phone = $2
+LIMIT 1
 `
 
-func (q *Queries) GetAccountByPhone(ctx context.Context, phone *string) (Account, error) {
-	row := q.db.QueryRow(ctx, getAccountByPhone, phone)
+type GetAccountByPhoneParams struct {
+	PhoneIndexes [][]byte `json:"phone_indexes"`
+	Phone        *string  `json:"phone"`
+}
+
+func (q *Queries) GetAccountByPhone(ctx context.Context, arg GetAccountByPhoneParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByPhone, arg.PhoneIndexes, arg.Phone)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -116,6 +209,174 @@ func (q *Queries) GetAccountByPhone(ctx context.Context, phone *string) (Account
 		&i.Role,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PhoneEncrypted,
+		&i.PhoneIndex,
+		&i.EmailEncrypted,
+		&i.EmailIndex,
+		&i.EncryptionKeyID,
+		&i.IndexKeyID,
 	)
 	return i, err
 }
+
+const listAccountEmails = `-- name: ListAccountEmails :many
+SELECT id, email FROM accounts
+WHERE id > $1
+  AND email IS NOT NULL
+ORDER BY id
+LIMIT $2
+`
+
+type ListAccountEmailsParams struct {
+	AfterID   uuid.UUID `json:"after_id"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+type ListAccountEmailsRow struct {
+	ID    uuid.UUID `json:"id"`
+	Email *string   `json:"email"`
+}
+
+func (q *Queries) ListAccountEmails(ctx context.Context, arg ListAccountEmailsParams) ([]ListAccountEmailsRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEmails, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEmailsRow{}
+	for rows.Next() {
+		var i ListAccountEmailsRow
+		if err := rows.Scan(&i.ID, &i.Email); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsToEncrypt = `-- name: ListAccountsToEncrypt :many
+SELECT id, phone, email, role, created_at, updated_at, phone_encrypted, phone_index, email_encrypted, email_index, encryption_key_id, index_key_id FROM accounts
+WHERE id > $1
+  AND (phone IS NOT NULL
+    OR email IS NOT NULL
+    OR encryption_key_id <> $2::text
+    OR index_key_id IS DISTINCT FROM $3::text)
+ORDER BY id
+LIMIT $4
+`
+
+type ListAccountsToEncryptParams struct {
+	AfterID      uuid.UUID `json:"after_id"`
+	PrimaryKeyID string    `json:"primary_key_id"`
+	IndexKeyID   string    `json:"index_key_id"`
+	BatchSize    int32     `json:"batch_size"`
+}
+
+func (q *Queries) ListAccountsToEncrypt(ctx context.Context, arg ListAccountsToEncryptParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsToEncrypt,
+		arg.AfterID,
+		arg.PrimaryKeyID,
+		arg.IndexKeyID,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Phone,
+			&i.Email,
+			&i.Role,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PhoneEncrypted,
+			&i.PhoneIndex,
+			&i.EmailEncrypted,
+			&i.EmailIndex,
+			&i.EncryptionKeyID,
+			&i.IndexKeyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccountIdentifier = `-- name: LockAccountIdentifier :exec
+SELECT pg_advisory_xact_lock($1::bigint)
+`
+
+// Serializes account creation for one identifier until the transaction ends.
+func (q *Queries) LockAccountIdentifier(ctx context.Context, key int64) error {
+	_, err := q.db.Exec(ctx, lockAccountIdentifier, key)
+	return err
+}
+
+const updateAccountEncryptedIdentifiers = `-- name: UpdateAccountEncryptedIdentifiers :execrows
+UPDATE accounts
+SET phone = NULL,
+    email = NULL,
+    phone_encrypted = $2,
+    phone_index = $3,
+    email_encrypted = $4,
+    email_index = $5,
+    encryption_key_id = $6,
+    index_key_id = $7
+WHERE id = $1
+`
+
+type UpdateAccountEncryptedIdentifiersParams struct {
+	ID              uuid.UUID `json:"id"`
+	PhoneEncrypted  []byte    `json:"phone_encrypted"`
+	PhoneIndex      []byte    `json:"phone_index"`
+	EmailEncrypted  []byte    `json:"email_encrypted"`
+	EmailIndex      []byte    `json:"email_index"`
+	EncryptionKeyID *string   `json:"encryption_key_id"`
+	IndexKeyID      *string   `json:"index_key_id"`
+}
+
+func (q *Queries) UpdateAccountEncryptedIdentifiers(ctx context.Context, arg UpdateAccountEncryptedIdentifiersParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAccountEncryptedIdentifiers,
+		arg.ID,
+		arg.PhoneEncrypted,
+		arg.PhoneIndex,
+		arg.EmailEncrypted,
+		arg.EmailIndex,
+		arg.EncryptionKeyID,
+		arg.IndexKeyID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateAccountRole = `-- name: UpdateAccountRole :execrows
+UPDATE accounts
+SET role = $2
+WHERE id = $1
+`
+
+type UpdateAccountRoleParams struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+func (q *Queries) UpdateAccountRole(ctx context.Context, arg UpdateAccountRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateAccountRole, arg.ID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -11,12 +11,91 @@ import (
 )
 
 type Account struct {
-	ID        uuid.UUID `json:"id"`
-	Phone     *string   `json:"phone"`
-	Email     *string   `json:"email"`
-	Role      string    `json:"role"`
+	ID              uuid.UUID `json:"id"`
+	Phone           *string   `json:"phone"`
+	Email           *string   `json:"email"`
+	Role            string    `json:"role"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	PhoneEncrypted  []byte    `json:"phone_encrypted"`
+	PhoneIndex      []byte    `json:"phone_index"`
+	EmailEncrypted  []byte    `json:"email_encrypted"`
+	EmailIndex      []byte    `json:"email_index"`
+	EncryptionKeyID *string   `json:"encryption_key_id"`
+	IndexKeyID      *string   `json:"index_key_id"`
+}
+
+type AccountEmailOriginal struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ApiKey struct {
+	ID         uuid.UUID  `json:"id"`
+	AccountID  uuid.UUID  `json:"account_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"key_hash"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AuthEvent struct {
+	ID             uuid.UUID  `json:"id"`
+	EventType      string     `json:"event_type"`
+	AccountID      *uuid.UUID `json:"account_id"`
+	IdentifierHash *string    `json:"identifier_hash"`
+	Ip             *string    `json:"ip"`
+	UserAgent      *string    `json:"user_agent"`
+	ClientID       *string    `json:"client_id"`
+	Outcome        string     `json:"outcome"`
+	ErrorCode      *string    `json:"error_code"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type Client struct {
+	ID                     string    `json:"id"`
+	Name                   string    `json:"name"`
+	Type                   string    `json:"type"`
+	GrantTypes             []string  `json:"grant_types"`
+	Scopes                 []string  `json:"scopes"`
+	AccessTokenTtlSeconds  *int32    `json:"access_token_ttl_seconds"`
+	RefreshTokenTtlSeconds *int32    `json:"refresh_token_ttl_seconds"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+	SecretHash             *string   `json:"secret_hash"`
+	PublicKey              *string   `json:"public_key"`
+}
+
+type Impersonation struct {
+	ID              uuid.UUID `json:"id"`
+	ActorAccountID  uuid.UUID `json:"actor_account_id"`
+	TargetAccountID uuid.UUID `json:"target_account_id"`
+	ClientID        *string   `json:"client_id"`
+	Reason          string    `json:"reason"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type Outbox struct {
+	ID            uuid.UUID `json:"id"`
+	AggregateType string    `json:"aggregate_type"`
+	AggregateID   uuid.UUID `json:"aggregate_id"`
+	EventType     string    `json:"event_type"`
+	Payload       []byte    `json:"payload"`
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     *string   `json:"last_error"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
 type RefreshToken struct {
@@ -25,4 +104,50 @@ type RefreshToken struct {
 	TokenHash string    `json:"token_hash"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
+	ClientID  *string   `json:"client_id"`
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RolePermission struct {
+	Role       string `json:"role"`
+	Permission string `json:"permission"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int32     `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         uuid.UUID `json:"id"`
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	StatusCode *int32    `json:"status_code"`
+	Error      *string   `json:"error"`
+	DurationMs int32     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID              uuid.UUID `json:"id"`
+	Url             string    `json:"url"`
+	EventTypes      []string  `json:"event_types"`
+	Secret          *string   `json:"secret"`
+	CreatedAt       time.Time `json:"created_at"`
+	SecretEncrypted []byte    `json:"secret_encrypted"`
+	EncryptionKeyID *string   `json:"encryption_key_id"`
 }
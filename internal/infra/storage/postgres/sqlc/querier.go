@@ -11,16 +11,72 @@ import (
 )
 
 type Querier interface {
-	AccountExistsByEmail(ctx context.Context, email *string) (bool, error)
-	AccountExistsByPhone(ctx context.Context, phone *string) (bool, error)
+	AccountExistsByEmail(ctx context.Context, arg AccountExistsByEmailParams) (bool, error)
+	AccountExistsByPhone(ctx context.Context, arg AccountExistsByPhoneParams) (bool, error)
+	AddRolePermission(ctx context.Context, arg AddRolePermissionParams) error
+	// Records the address being replaced in account_email_originals. Nothing is
+	// changed when the stored address is no longer the one that was read.
+	CanonicalizeAccountEmail(ctx context.Context, arg CanonicalizeAccountEmailParams) (int64, error)
+	// Only the oldest unpublished event of an aggregate can be claimed, so an
+	// event waiting for its retry holds back the later ones of its aggregate.
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CountAccountsWithPermission(ctx context.Context, permission string) (int64, error)
+	// Creates the account unless the identifier is taken, whether stored in
+	// plaintext or indexed under any blind index key. Run LockAccountIdentifier
+	// first in the same transaction, so concurrent sign-ups cannot both pass.
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (uuid.UUID, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) error
+	CreateAuthEvent(ctx context.Context, arg CreateAuthEventParams) error
+	CreateImpersonation(ctx context.Context, arg CreateImpersonationParams) error
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error
 	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error
+	CreateRole(ctx context.Context, arg CreateRoleParams) (int64, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) error
+	DeleteAuthEventsBefore(ctx context.Context, arg DeleteAuthEventsBeforeParams) (int64, error)
+	DeleteOutboxEvent(ctx context.Context, id uuid.UUID) error
 	DeleteRefreshTokenByHash(ctx context.Context, tokenHash string) (int64, error)
+	DeleteRefreshTokenByHashAndClientID(ctx context.Context, arg DeleteRefreshTokenByHashAndClientIDParams) (int64, error)
 	DeleteRefreshTokensByAccountID(ctx context.Context, accountID uuid.UUID) error
-	GetAccountByEmail(ctx context.Context, email *string) (Account, error)
+	DeleteRole(ctx context.Context, name string) (int64, error)
+	DeleteRolePermissions(ctx context.Context, role string) error
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (int64, error)
+	GetAccountByEmail(ctx context.Context, arg GetAccountByEmailParams) (Account, error)
 	GetAccountByID(ctx context.Context, id uuid.UUID) (Account, error)
-	GetAccountByPhone(ctx context.Context, phone *string) (Account, error)
+	GetAccountByPhone(ctx context.Context, arg GetAccountByPhoneParams) (Account, error)
+	GetActiveApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetClientByID(ctx context.Context, id string) (Client, error)
+	// Returns 0 when no client sets its own access token TTL.
+	GetLongestAccessTokenTTL(ctx context.Context) (int32, error)
 	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id uuid.UUID) (WebhookSubscription, error)
+	ListAccountEmails(ctx context.Context, arg ListAccountEmailsParams) ([]ListAccountEmailsRow, error)
+	ListAccountsToEncrypt(ctx context.Context, arg ListAccountsToEncryptParams) ([]Account, error)
+	ListApiKeysByAccountID(ctx context.Context, accountID uuid.UUID) ([]ApiKey, error)
+	ListAuthEvents(ctx context.Context, arg ListAuthEventsParams) ([]AuthEvent, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	ListPermissionsByRole(ctx context.Context, role string) ([]string, error)
+	ListRolePermissions(ctx context.Context) ([]RolePermission, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID uuid.UUID) ([]WebhookDeliveryAttempt, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsForEvent(ctx context.Context, eventType string) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsToEncrypt(ctx context.Context, arg ListWebhookSubscriptionsToEncryptParams) ([]WebhookSubscription, error)
+	// Serializes account creation for one identifier until the transaction ends.
+	LockAccountIdentifier(ctx context.Context, key int64) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error
+	MarkWebhookDeliverySucceeded(ctx context.Context, arg MarkWebhookDeliverySucceededParams) error
+	ReplayWebhookDelivery(ctx context.Context, id uuid.UUID) (int64, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (int64, error)
+	RoleExists(ctx context.Context, name string) (bool, error)
+	UpdateAccountEncryptedIdentifiers(ctx context.Context, arg UpdateAccountEncryptedIdentifiersParams) (int64, error)
+	UpdateAccountRole(ctx context.Context, arg UpdateAccountRoleParams) (int64, error)
+	UpdateApiKeyLastUsedAt(ctx context.Context, id uuid.UUID) error
+	UpdateWebhookSubscriptionEncryptedSecret(ctx context.Context, arg UpdateWebhookSubscriptionEncryptedSecretParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
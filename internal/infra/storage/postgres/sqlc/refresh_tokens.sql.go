@@ -13,13 +13,14 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :exec
-INSERT INTO refresh_tokens (id, account_id, token_hash, expires_at)
-VALUES ($1,$2,$3,$4)
+INSERT INTO refresh_tokens (id, account_id, client_id, token_hash, expires_at)
+VALUES ($1,$2,$3,$4,$5)
 `
 
 type CreateRefreshTokenParams struct {
 	ID        uuid.UUID `json:"id"`
 	AccountID uuid.UUID `json:"account_id"`
+	ClientID  *string   `json:"client_id"`
 	TokenHash string    `json:"token_hash"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -28,6 +29,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 	_, err := q.db.Exec(ctx, createRefreshToken,
 		arg.ID,
 		arg.AccountID,
+		arg.ClientID,
 		arg.TokenHash,
 		arg.ExpiresAt,
 	)
@@ -47,6 +49,24 @@ func (q *Queries) DeleteRefreshTokenByHash(ctx context.Context, tokenHash string
 	return result.RowsAffected(), nil
 }
 
+const deleteRefreshTokenByHashAndClientID = `-- name: DeleteRefreshTokenByHashAndClientID :execrows
+DELETE FROM refresh_tokens
+WHERE token_hash = $1 AND client_id = $2
+`
+
+type DeleteRefreshTokenByHashAndClientIDParams struct {
+	TokenHash string  `json:"token_hash"`
+	ClientID  *string `json:"client_id"`
+}
+
+func (q *Queries) DeleteRefreshTokenByHashAndClientID(ctx context.Context, arg DeleteRefreshTokenByHashAndClientIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRefreshTokenByHashAndClientID, arg.TokenHash, arg.ClientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteRefreshTokensByAccountID = `-- name: DeleteRefreshTokensByAccountID :exec
 DELETE FROM refresh_tokens
 WHERE account_id = $1
@@ -58,7 +78,7 @@ func (q *Queries) DeleteRefreshTokensByAccountID(ctx context.Context, accountID
 }
 
 const getRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
-SELECT id, account_id, token_hash, expires_at, created_at FROM refresh_tokens
+SELECT id, account_id, token_hash, expires_at, created_at, client_id FROM refresh_tokens
 WHERE token_hash = $1 AND expires_at > NOW()
 `
 
@@ -71,6 +91,7 @@ func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.ClientID,
 	)
 	return i, err
 }
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the bucket for the time elapsed since the last
+// call and takes one token, all in one atomic step so concurrent replicas
+// share the same budget. The time comes from Redis, so clock skew between
+// replicas cannot refill or starve a bucket. It returns
+// {allowed, retry_after_ms}.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local refill_ms = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / refill_ms)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = math.ceil((1 - tokens) * refill_ms)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * refill_ms))
+
+return {allowed, retry_after}
+`)
+
+// TakeToken takes one token from the bucket at key. The bucket holds up to
+// capacity tokens and regains one every refill.
+func (c *Client) TakeToken(ctx context.Context, key string, capacity int, refill time.Duration) (bool, time.Duration, error) {
+	res, err := tokenBucketScript.Run(ctx, c.client, []string{key}, capacity, float64(refill)/float64(time.Millisecond)).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
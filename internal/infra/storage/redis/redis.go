@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 	"github.com/teacinema-go/auth-service/internal/config"
 )
@@ -20,6 +21,10 @@ func NewClient(ctx context.Context, cfg *config.Redis) (*Client, error) {
 		Password: cfg.Password,
 	})
 
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("failed to instrument redis client: %w", err)
+	}
+
 	if err := client.Ping(ctx).Err(); err != nil {
 		fmt.Printf("failed to connect to redis server: %s\n", err.Error())
 		return nil, err
@@ -38,6 +43,14 @@ func (c *Client) Set(ctx context.Context, key string, value any, ttl time.Durati
 	return c.client.Set(ctx, key, value, ttl).Err()
 }
 
+func (c *Client) MGet(ctx context.Context, keys ...string) ([]any, error) {
+	return c.client.MGet(ctx, keys...).Result()
+}
+
+func (c *Client) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, key, value, ttl).Result()
+}
+
 func (c *Client) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
@@ -45,3 +58,11 @@ func (c *Client) Delete(ctx context.Context, key string) error {
 func (c *Client) Close() error {
 	return c.client.Close()
 }
+
+func (c *Client) PoolStats() *redis.PoolStats {
+	return c.client.PoolStats()
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
@@ -0,0 +1,22 @@
+package requestinfo
+
+import (
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is used both as gRPC metadata key and as HTTP header.
+const RequestIDHeader = "x-request-id"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDOrNew keeps a caller-supplied request ID when it is safe to log
+// and echo back, and generates a new one otherwise.
+func RequestIDOrNew(requestID string) string {
+	if requestIDPattern.MatchString(requestID) {
+		return requestID
+	}
+
+	return uuid.NewString()
+}
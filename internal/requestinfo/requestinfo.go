@@ -0,0 +1,24 @@
+package requestinfo
+
+import "context"
+
+type Info struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	// PeerSANs lists the subject alternative names of a verified client
+	// certificate, set only when the listener requires mutual TLS. Internal
+	// callers are identified by these, e.g. a spiffe:// URI or a DNS name.
+	PeerSANs []string
+}
+
+type infoKey struct{}
+
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	"github.com/teacinema-go/core/logger"
+)
+
+const (
+	maxIPLength        = 45
+	maxUserAgentLength = 512
+	maxClientIDLength  = 64
+	maxErrorCodeLength = 100
+)
+
+type Repository interface {
+	CreateAuthEvent(ctx context.Context, arg dto.CreateAuthEventParams) error
+	DeleteAuthEventsBefore(ctx context.Context, before time.Time, batchSize int32) (int64, error)
+}
+
+type PostgresAuditRecorder struct {
+	repo          Repository
+	identifierKey []byte
+}
+
+func NewPostgresAuditRecorder(repo Repository, identifierKey string) *PostgresAuditRecorder {
+	return &PostgresAuditRecorder{
+		repo:          repo,
+		identifierKey: []byte(identifierKey),
+	}
+}
+
+// Record never fails the caller: an audit write error is logged and the
+// request proceeds. The write is detached from request cancellation so a
+// client hanging up does not drop its own trail entry.
+func (r *PostgresAuditRecorder) Record(ctx context.Context, event dto.AuthEvent) {
+	id, err := valueobject.NewID()
+	if err != nil {
+		logger.Error("failed to generate auth event ID", "event_type", event.Type, "error", err)
+		return
+	}
+
+	info := requestinfo.FromContext(ctx)
+
+	err = r.repo.CreateAuthEvent(context.WithoutCancel(ctx), dto.CreateAuthEventParams{
+		ID:             id,
+		Type:           event.Type,
+		AccountID:      event.AccountID,
+		IdentifierHash: r.hashIdentifier(event.Identifier),
+		IP:             truncate(info.IP, maxIPLength),
+		UserAgent:      truncate(info.UserAgent, maxUserAgentLength),
+		ClientID:       truncate(event.ClientID, maxClientIDLength),
+		Outcome:        event.Outcome,
+		ErrorCode:      truncate(event.ErrorCode, maxErrorCodeLength),
+	})
+	if err != nil {
+		logger.Error("failed to record auth event", "event_type", event.Type, "error", err)
+	}
+}
+
+// hashIdentifier uses an HMAC because phone numbers and emails are easy to
+// enumerate, so a plain hash could be reversed by hashing candidates.
+func (r *PostgresAuditRecorder) hashIdentifier(identifier valueobject.Identifier) string {
+	if identifier == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, r.identifierKey)
+	mac.Write([]byte(identifier))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
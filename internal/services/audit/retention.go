@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/core/logger"
+)
+
+const retentionBatchSize = 10000
+
+type RetentionJob struct {
+	repo      Repository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewRetentionJob(repo Repository, retention time.Duration, interval time.Duration) *RetentionJob {
+	return &RetentionJob{
+		repo:      repo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+func (j *RetentionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes in batches so a large backlog does not hold one long
+// transaction over the table.
+func (j *RetentionJob) purge(ctx context.Context) {
+	before := time.Now().Add(-j.retention)
+
+	var total int64
+	for {
+		deleted, err := j.repo.DeleteAuthEventsBefore(ctx, before, retentionBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("failed to purge auth events", "error", err)
+			}
+			return
+		}
+
+		total += deleted
+		if deleted < retentionBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.Info("expired auth events purged", "count", total)
+	}
+}
@@ -0,0 +1,71 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+func newStubProvider(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if got := r.PostForm.Get("secret"); got != "secret" {
+			t.Errorf("secret = %q, want %q", got, "secret")
+		}
+		if got := r.PostForm.Get("response"); got != "token" {
+			t.Errorf("response = %q, want %q", got, "token")
+		}
+		if got := r.PostForm.Get("remoteip"); got != "203.0.113.7" {
+			t.Errorf("remoteip = %q, want %q", got, "203.0.113.7")
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCaptchaVerifierVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     bool
+		wantInvalid bool
+	}{
+		{name: "success", status: http.StatusOK, body: `{"success":true}`},
+		{name: "score above minimum", status: http.StatusOK, body: `{"success":true,"score":0.9}`},
+		{name: "score below minimum", status: http.StatusOK, body: `{"success":true,"score":0.1}`, wantErr: true, wantInvalid: true},
+		{name: "rejected", status: http.StatusOK, body: `{"success":false,"error-codes":["invalid-input-response"]}`, wantErr: true, wantInvalid: true},
+		{name: "provider error", status: http.StatusInternalServerError, body: `{}`, wantErr: true},
+		{name: "malformed", status: http.StatusOK, body: `not json`, wantErr: true},
+		{name: "oversized", status: http.StatusOK, body: `{"success":true,"error-codes":["` + strings.Repeat("x", maxSiteverifyResponseSize) + `"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newStubProvider(t, tt.status, tt.body)
+			v := NewCaptchaVerifier(time.Second, srv.URL, "secret", 0.5)
+
+			err := v.Verify(context.Background(), "token", "203.0.113.7")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, appErrors.ErrInvalidChallenge); got != tt.wantInvalid {
+				t.Errorf("Verify() error = %v, want ErrInvalidChallenge %v", err, tt.wantInvalid)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/core/logger"
+)
+
+const (
+	knownIPKeyPrefix = "challenge:ip:"
+	burstKeyPrefix   = "challenge:burst:"
+)
+
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+	TakeToken(ctx context.Context, key string, capacity int, refill time.Duration) (bool, time.Duration, error)
+}
+
+// Verifier checks a client's answer to one kind of challenge.
+type Verifier interface {
+	Verify(ctx context.Context, response string, remoteIP string) error
+}
+
+// Gate decides whether a SendOtp caller must solve a challenge first. Risk is
+// elevated for IPs that have never passed a challenge, for phone numbers in
+// high-cost ranges and for IPs sending in bursts.
+type Gate struct {
+	store            Store
+	captcha          Verifier
+	captchaProvider  string
+	pow              *ProofOfWork
+	highRiskPrefixes []string
+	burstLimit       int
+	burstPeriod      time.Duration
+	knownIPTTL       time.Duration
+}
+
+// NewGate accepts a nil captcha or pow to disable that kind of challenge.
+func NewGate(
+	store Store,
+	captcha Verifier,
+	captchaProvider string,
+	pow *ProofOfWork,
+	highRiskPrefixes []string,
+	burstLimit int,
+	burstPeriod time.Duration,
+	knownIPTTL time.Duration,
+) *Gate {
+	return &Gate{
+		store:            store,
+		captcha:          captcha,
+		captchaProvider:  captchaProvider,
+		pow:              pow,
+		highRiskPrefixes: highRiskPrefixes,
+		burstLimit:       burstLimit,
+		burstPeriod:      burstPeriod,
+		knownIPTTL:       knownIPTTL,
+	}
+}
+
+// Check returns nil when the request may proceed. Otherwise it returns
+// ErrChallengeRequired or ErrInvalidChallenge together with the challenge the
+// client should solve next.
+func (g *Gate) Check(ctx context.Context, req dto.ChallengeRequest) (*dto.Challenge, error) {
+	elevated, err := g.riskElevated(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !elevated {
+		return nil, nil
+	}
+
+	if req.Response == "" {
+		return g.challenge(appErrors.ErrChallengeRequired)
+	}
+
+	verifier := g.verifier(req.Type)
+	if verifier == nil {
+		return g.challenge(appErrors.ErrInvalidChallenge)
+	}
+
+	if err = verifier.Verify(ctx, req.Response, req.IP); err != nil {
+		if errors.Is(err, appErrors.ErrInvalidChallenge) {
+			return g.challenge(err)
+		}
+		return nil, err
+	}
+
+	if req.IP != "" {
+		if err = g.store.Set(ctx, knownIPKeyPrefix+req.IP, 1, g.knownIPTTL); err != nil {
+			logger.Warn("failed to remember challenge ip", "error", err)
+		}
+	}
+
+	return nil, nil
+}
+
+func (g *Gate) riskElevated(ctx context.Context, req dto.ChallengeRequest) (bool, error) {
+	elevated := false
+
+	if req.IdentifierType == valueobject.IdentifierTypePhone {
+		for _, prefix := range g.highRiskPrefixes {
+			if strings.HasPrefix(string(req.Identifier), prefix) {
+				elevated = true
+				break
+			}
+		}
+	}
+
+	if req.IP == "" {
+		return elevated, nil
+	}
+
+	// The burst bucket is drained on every request, even when risk is
+	// already elevated, so a solved challenge does not reset it.
+	if g.burstLimit > 0 {
+		allowed, _, err := g.store.TakeToken(ctx, burstKeyPrefix+req.IP, g.burstLimit, g.burstPeriod/time.Duration(g.burstLimit))
+		if err != nil {
+			return false, fmt.Errorf("failed to check challenge burst: %w", err)
+		}
+		if !allowed {
+			elevated = true
+		}
+	}
+
+	if !elevated {
+		_, err := g.store.Get(ctx, knownIPKeyPrefix+req.IP)
+		if err != nil {
+			if !errors.Is(err, redis.Nil) {
+				return false, fmt.Errorf("failed to check challenge ip: %w", err)
+			}
+			elevated = true
+		}
+	}
+
+	return elevated, nil
+}
+
+func (g *Gate) verifier(challengeType valueobject.ChallengeType) Verifier {
+	switch challengeType {
+	case valueobject.ChallengeTypeCaptcha:
+		return g.captcha
+	case valueobject.ChallengeTypeProofOfWork:
+		if g.pow != nil {
+			return g.pow
+		}
+	}
+
+	return nil
+}
+
+func (g *Gate) challenge(cause error) (*dto.Challenge, error) {
+	res := &dto.Challenge{}
+
+	if g.captcha != nil {
+		res.Types = append(res.Types, valueobject.ChallengeTypeCaptcha)
+		res.CaptchaProvider = g.captchaProvider
+	}
+
+	if g.pow != nil {
+		powChallenge, err := g.pow.Issue()
+		if err != nil {
+			return nil, err
+		}
+		res.Types = append(res.Types, valueobject.ChallengeTypeProofOfWork)
+		res.PowChallenge = powChallenge
+		res.PowDifficulty = g.pow.Difficulty()
+	}
+
+	return res, cause
+}
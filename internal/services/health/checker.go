@@ -0,0 +1,154 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/teacinema-go/core/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthv1 "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Liveness is reported on the empty service name as well, which is what
+// plain health probes ask for.
+const (
+	LivenessService  = "liveness"
+	ReadinessService = "readiness"
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type Checker struct {
+	server   *health.Server
+	deps     map[string]Pinger
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.Mutex
+	failed   map[string]bool
+	draining bool
+}
+
+func NewChecker(deps map[string]Pinger, interval time.Duration, timeout time.Duration) *Checker {
+	server := health.NewServer()
+	server.SetServingStatus("", healthv1.HealthCheckResponse_SERVING)
+	server.SetServingStatus(LivenessService, healthv1.HealthCheckResponse_SERVING)
+	server.SetServingStatus(ReadinessService, healthv1.HealthCheckResponse_NOT_SERVING)
+
+	return &Checker{
+		server:   server,
+		deps:     deps,
+		interval: interval,
+		timeout:  timeout,
+		failed:   make(map[string]bool, len(deps)),
+	}
+}
+
+func (c *Checker) Register(registrar grpc.ServiceRegistrar) {
+	healthv1.RegisterHealthServer(registrar, c.server)
+}
+
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown reports readiness as NOT_SERVING for good, so load balancers stop
+// routing new calls while in-flight ones drain. Liveness keeps SERVING: the
+// process is healthy, and failing it would get it killed mid-drain.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.draining = true
+	c.server.SetServingStatus(ReadinessService, healthv1.HealthCheckResponse_NOT_SERVING)
+}
+
+func (c *Checker) LivenessHandler() http.HandlerFunc {
+	return c.handler(LivenessService)
+}
+
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return c.handler(ReadinessService)
+}
+
+func (c *Checker) handler(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := c.server.Check(r.Context(), &healthv1.HealthCheckRequest{Service: service})
+		if err != nil || resp.GetStatus() != healthv1.HealthCheckResponse_SERVING {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(healthv1.HealthCheckResponse_NOT_SERVING.String()))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(healthv1.HealthCheckResponse_SERVING.String()))
+	}
+}
+
+func (c *Checker) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for name, dep := range c.deps {
+		wg.Go(func() {
+			pingCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			err := dep.Ping(pingCtx)
+			if ctx.Err() != nil {
+				return
+			}
+			c.setFailed(name, err)
+		})
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.draining {
+		return
+	}
+
+	status := healthv1.HealthCheckResponse_SERVING
+	for _, failed := range c.failed {
+		if failed {
+			status = healthv1.HealthCheckResponse_NOT_SERVING
+			break
+		}
+	}
+	c.server.SetServingStatus(ReadinessService, status)
+}
+
+func (c *Checker) setFailed(name string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failed := err != nil
+	if failed != c.failed[name] {
+		if failed {
+			logger.Warn("dependency unhealthy", "dependency", name, "error", err)
+		} else {
+			logger.Info("dependency healthy again", "dependency", name)
+		}
+	}
+	c.failed[name] = failed
+}
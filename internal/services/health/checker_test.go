@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestCheckerShutdownOnlyFailsReadiness(t *testing.T) {
+	healthy := pingerFunc(func(context.Context) error { return nil })
+	c := NewChecker(map[string]Pinger{"postgres": healthy}, time.Second, time.Second)
+
+	c.check(context.Background())
+	assertStatus(t, c.ReadinessHandler(), http.StatusOK)
+
+	c.Shutdown()
+	// A check finishing after Shutdown must not report ready again.
+	c.check(context.Background())
+
+	assertStatus(t, c.ReadinessHandler(), http.StatusServiceUnavailable)
+	assertStatus(t, c.LivenessHandler(), http.StatusOK)
+}
+
+func assertStatus(t *testing.T, handler http.HandlerFunc, want int) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != want {
+		t.Fatalf("status = %d, want %d", rec.Code, want)
+	}
+}
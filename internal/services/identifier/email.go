@@ -0,0 +1,182 @@
+package identifier
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/core/logger"
+	"golang.org/x/net/idna"
+)
+
+// MXResolver is satisfied by *net.Resolver.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+type providerRule struct {
+	canonicalDomain string
+	removeDots      bool
+}
+
+// providerRules lists mailboxes known to ignore dots and +tags in the local
+// part, so that alias addresses cannot be used to open extra accounts.
+var providerRules = map[string]providerRule{
+	"gmail.com":      {canonicalDomain: "gmail.com", removeDots: true},
+	"googlemail.com": {canonicalDomain: "gmail.com", removeDots: true},
+	"outlook.com":    {canonicalDomain: "outlook.com"},
+	"hotmail.com":    {canonicalDomain: "hotmail.com"},
+	"live.com":       {canonicalDomain: "live.com"},
+	"icloud.com":     {canonicalDomain: "icloud.com"},
+	"me.com":         {canonicalDomain: "me.com"},
+	"fastmail.com":   {canonicalDomain: "fastmail.com"},
+	"proton.me":      {canonicalDomain: "proton.me"},
+	"protonmail.com": {canonicalDomain: "protonmail.com"},
+}
+
+type EmailPolicy struct {
+	providerRules     bool
+	disposableDomains map[string]struct{}
+	mxResolver        MXResolver
+	mxTimeout         time.Duration
+}
+
+// NewEmailPolicy accepts a nil mxResolver to skip the MX check.
+func NewEmailPolicy(providerRules bool, disposableDomains []string, mxResolver MXResolver, mxTimeout time.Duration) *EmailPolicy {
+	domains := make(map[string]struct{}, len(disposableDomains))
+	for _, domain := range disposableDomains {
+		domains[domain] = struct{}{}
+	}
+
+	return &EmailPolicy{
+		providerRules:     providerRules,
+		disposableDomains: domains,
+		mxResolver:        mxResolver,
+		mxTimeout:         mxTimeout,
+	}
+}
+
+// Normalize lowercases the address, converts the domain to its ASCII (IDNA)
+// form and, when enabled, applies the provider rules.
+func (p *EmailPolicy) Normalize(email valueobject.Identifier) (valueobject.Identifier, error) {
+	local, domain, found := strings.Cut(strings.TrimSpace(string(email)), "@")
+	if !found || local == "" || domain == "" {
+		return "", appErrors.ErrInvalidEmail
+	}
+
+	domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return "", appErrors.ErrInvalidEmail
+	}
+	local = strings.ToLower(local)
+
+	if p.providerRules {
+		if rule, ok := providerRules[domain]; ok {
+			local, _, _ = strings.Cut(local, "+")
+			if rule.removeDots {
+				local = strings.ReplaceAll(local, ".", "")
+			}
+			if local == "" {
+				return "", appErrors.ErrInvalidEmail
+			}
+			domain = rule.canonicalDomain
+		}
+	}
+
+	return valueobject.Identifier(local + "@" + domain), nil
+}
+
+// Allow returns ErrEmailNotAllowed for a normalized address on a disposable
+// domain or a domain that cannot receive mail.
+func (p *EmailPolicy) Allow(ctx context.Context, email valueobject.Identifier) error {
+	_, domain, found := strings.Cut(string(email), "@")
+	if !found || domain == "" {
+		return appErrors.ErrInvalidEmail
+	}
+
+	if p.isDisposable(domain) {
+		return fmt.Errorf("%w: disposable domain %s", appErrors.ErrEmailNotAllowed, domain)
+	}
+
+	if p.mxResolver != nil {
+		return p.checkMX(ctx, domain)
+	}
+
+	return nil
+}
+
+// isDisposable also matches subdomains of a listed domain.
+func (p *EmailPolicy) isDisposable(domain string) bool {
+	for {
+		if _, ok := p.disposableDomains[domain]; ok {
+			return true
+		}
+
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			return false
+		}
+		domain = parent
+	}
+}
+
+// checkMX rejects domains that do not exist or publish a null MX (RFC 7505).
+// Other lookup failures let the address through, so a DNS outage does not
+// block sign-ups.
+func (p *EmailPolicy) checkMX(ctx context.Context, domain string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.mxTimeout)
+	defer cancel()
+
+	records, err := p.mxResolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return fmt.Errorf("%w: domain %s has no mail server", appErrors.ErrEmailNotAllowed, domain)
+		}
+		logger.Warn("mx lookup failed, allowing email", "domain", domain, "error", err)
+		return nil
+	}
+
+	if len(records) == 0 || (len(records) == 1 && records[0].Host == ".") {
+		return fmt.Errorf("%w: domain %s has no mail server", appErrors.ErrEmailNotAllowed, domain)
+	}
+
+	return nil
+}
+
+// LoadDomainList reads one domain per line, ignoring blank lines and lines
+// starting with #.
+func LoadDomainList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain list: %w", err)
+	}
+	defer file.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		domain, err := idna.Lookup.ToASCII(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid domain %q in domain list: %w", line, err)
+		}
+		domains = append(domains, domain)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+
+	return domains, nil
+}
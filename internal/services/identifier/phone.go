@@ -0,0 +1,108 @@
+package identifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+var phoneNumberTypes = map[string]phonenumbers.PhoneNumberType{
+	"fixed_line":      phonenumbers.FIXED_LINE,
+	"mobile":          phonenumbers.MOBILE,
+	"toll_free":       phonenumbers.TOLL_FREE,
+	"premium_rate":    phonenumbers.PREMIUM_RATE,
+	"shared_cost":     phonenumbers.SHARED_COST,
+	"voip":            phonenumbers.VOIP,
+	"personal_number": phonenumbers.PERSONAL_NUMBER,
+	"pager":           phonenumbers.PAGER,
+	"uan":             phonenumbers.UAN,
+	"voicemail":       phonenumbers.VOICEMAIL,
+}
+
+// PhonePolicy decides which phone numbers may receive an OTP, based on the
+// libphonenumber metadata for the number's region and type.
+type PhonePolicy struct {
+	allowedRegions map[string]struct{}
+	deniedRegions  map[string]struct{}
+	blockedTypes   map[phonenumbers.PhoneNumberType]struct{}
+}
+
+// NewPhonePolicy takes ISO 3166-1 alpha-2 region codes and type names such as
+// "premium_rate". An empty allow list allows every region not denied.
+func NewPhonePolicy(allowedRegions []string, deniedRegions []string, blockedTypes []string) (*PhonePolicy, error) {
+	p := &PhonePolicy{
+		allowedRegions: regionSet(allowedRegions),
+		deniedRegions:  regionSet(deniedRegions),
+		blockedTypes:   make(map[phonenumbers.PhoneNumberType]struct{}, len(blockedTypes)),
+	}
+
+	for _, name := range blockedTypes {
+		numberType, ok := phoneNumberTypes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown phone number type %q", name)
+		}
+		p.blockedTypes[numberType] = struct{}{}
+	}
+
+	return p, nil
+}
+
+// Normalize returns the canonical E.164 form of phone, or ErrInvalidE164Phone
+// when libphonenumber does not consider it a real number.
+func (p *PhonePolicy) Normalize(phone valueobject.Identifier) (valueobject.Identifier, error) {
+	number, err := parsePhone(phone)
+	if err != nil {
+		return "", err
+	}
+
+	return valueobject.Identifier(phonenumbers.Format(number, phonenumbers.E164)), nil
+}
+
+// Allow returns ErrPhoneNotAllowed when the region or type of phone is
+// rejected by the policy.
+func (p *PhonePolicy) Allow(phone valueobject.Identifier) error {
+	number, err := parsePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	region := phonenumbers.GetRegionCodeForNumber(number)
+	if _, denied := p.deniedRegions[region]; denied {
+		return fmt.Errorf("%w: region %s is denied", appErrors.ErrPhoneNotAllowed, region)
+	}
+	if len(p.allowedRegions) > 0 {
+		if _, allowed := p.allowedRegions[region]; !allowed {
+			return fmt.Errorf("%w: region %s is not allowed", appErrors.ErrPhoneNotAllowed, region)
+		}
+	}
+
+	if _, blocked := p.blockedTypes[phonenumbers.GetNumberType(number)]; blocked {
+		return fmt.Errorf("%w: number type is blocked", appErrors.ErrPhoneNotAllowed)
+	}
+
+	return nil
+}
+
+func parsePhone(phone valueobject.Identifier) (*phonenumbers.PhoneNumber, error) {
+	number, err := phonenumbers.Parse(string(phone), "")
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return nil, appErrors.ErrInvalidE164Phone
+	}
+
+	return number, nil
+}
+
+func regionSet(regions []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(regions))
+	for _, region := range regions {
+		region = strings.ToUpper(strings.TrimSpace(region))
+		if region != "" {
+			set[region] = struct{}{}
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,50 @@
+package identifier
+
+import (
+	"context"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+// Policy canonicalizes identifiers, so the same person always maps to the
+// same OTP cache key and account row, and enforces the per-type rules on
+// sign-up.
+type Policy struct {
+	phone *PhonePolicy
+	email *EmailPolicy
+}
+
+func NewPolicy(phone *PhonePolicy, email *EmailPolicy) *Policy {
+	return &Policy{
+		phone: phone,
+		email: email,
+	}
+}
+
+// Normalize returns the canonical form of identifier, which callers should
+// still run through Identifier.Validate.
+func (p *Policy) Normalize(identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (valueobject.Identifier, error) {
+	switch identifierType {
+	case valueobject.IdentifierTypePhone:
+		return p.phone.Normalize(identifier)
+	case valueobject.IdentifierTypeEmail:
+		return p.email.Normalize(identifier)
+	default:
+		return "", appErrors.ErrInvalidIdentifierType
+	}
+}
+
+// Allow reports whether a new account may be opened for a normalized
+// identifier. It is only checked on sign-up, so tightening the rules never
+// locks out existing accounts.
+func (p *Policy) Allow(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) error {
+	switch identifierType {
+	case valueobject.IdentifierTypePhone:
+		return p.phone.Allow(identifier)
+	case valueobject.IdentifierTypeEmail:
+		return p.email.Allow(ctx, identifier)
+	default:
+		return appErrors.ErrInvalidIdentifierType
+	}
+}
@@ -0,0 +1,52 @@
+package identifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+)
+
+func TestPolicyNormalizeIgnoresSignUpRules(t *testing.T) {
+	phone, err := NewPhonePolicy(nil, []string{"GB"}, []string{"premium_rate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := NewEmailPolicy(true, []string{"mailinator.com"}, nil, 0)
+	policy := NewPolicy(phone, email)
+
+	tests := []struct {
+		name           string
+		identifier     valueobject.Identifier
+		identifierType valueobject.IdentifierType
+		want           valueobject.Identifier
+		wantErr        error
+	}{
+		{name: "denied region", identifier: "+44 20 7946 0958", identifierType: valueobject.IdentifierTypePhone, want: "+442079460958", wantErr: appErrors.ErrPhoneNotAllowed},
+		{name: "allowed phone", identifier: "+1 650-253-0000", identifierType: valueobject.IdentifierTypePhone, want: "+16502530000"},
+		{name: "disposable email", identifier: "Someone@Mailinator.com", identifierType: valueobject.IdentifierTypeEmail, want: "someone@mailinator.com", wantErr: appErrors.ErrEmailNotAllowed},
+		{name: "gmail alias", identifier: "Some.One+news@googlemail.com", identifierType: valueobject.IdentifierTypeEmail, want: "someone@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := policy.Normalize(tt.identifier, tt.identifierType)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+
+			err = policy.Allow(context.Background(), got, tt.identifierType)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Allow() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Allow() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
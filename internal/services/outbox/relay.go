@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+	"github.com/teacinema-go/core/logger"
+)
+
+const (
+	leaseDuration  = time.Minute
+	publishTimeout = 10 * time.Second
+	baseBackoff    = time.Second
+	maxBackoff     = 5 * time.Minute
+)
+
+type Repository interface {
+	ClaimOutboxEvents(ctx context.Context, lockedUntil time.Time, batchSize int32) ([]*entities.OutboxEvent, error)
+	DeleteOutboxEvent(ctx context.Context, eventID valueobject.ID) error
+	MarkOutboxEventFailed(ctx context.Context, arg dto.OutboxEventFailure) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event *entities.OutboxEvent) error
+}
+
+// Relay delivers outbox rows at least once. Rows are claimed with a lease
+// instead of a long-lived lock, so several replicas can relay concurrently
+// and a crashed relay's rows become visible again once the lease expires.
+type Relay struct {
+	repo         Repository
+	publisher    EventPublisher
+	batchSize    int32
+	pollInterval time.Duration
+}
+
+func NewRelay(repo Repository, publisher EventPublisher, batchSize int32, pollInterval time.Duration) *Relay {
+	return &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Each claim takes one event per aggregate, so keep claiming while
+		// there is work instead of draining a busy aggregate once per tick.
+		claimed := r.relayBatch(ctx)
+		if claimed > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) int {
+	events, err := r.repo.ClaimOutboxEvents(ctx, time.Now().Add(leaseDuration), r.batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("failed to claim outbox events", "error", err)
+		}
+		return 0
+	}
+
+	// The claim returns at most one event per aggregate, the oldest one still
+	// unpublished, so a failed event holds back the rest of its aggregate
+	// until its retry succeeds, across batches and replicas alike.
+	slices.SortFunc(events, func(a, b *entities.OutboxEvent) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err = r.publish(ctx, event); err != nil {
+			r.markFailed(ctx, event, err)
+			continue
+		}
+
+		if err = r.repo.DeleteOutboxEvent(ctx, event.ID); err != nil {
+			logger.Error("failed to delete published outbox event", "event_id", event.ID.ToString(), "error", err)
+		}
+	}
+
+	return len(events)
+}
+
+func (r *Relay) publish(ctx context.Context, event *entities.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	return r.publisher.Publish(ctx, event)
+}
+
+func (r *Relay) markFailed(ctx context.Context, event *entities.OutboxEvent, publishErr error) {
+	delay := utils.Backoff(event.Attempts, baseBackoff, maxBackoff)
+
+	logger.Warn("failed to publish outbox event",
+		"event_id", event.ID.ToString(),
+		"event_type", event.EventType,
+		"attempts", event.Attempts+1,
+		"retry_in", delay.String(),
+		"error", publishErr,
+	)
+
+	err := r.repo.MarkOutboxEventFailed(ctx, dto.OutboxEventFailure{
+		ID:            event.ID,
+		NextAttemptAt: time.Now().Add(delay),
+		LastError:     publishErr.Error(),
+	})
+	if err != nil {
+		logger.Error("failed to mark outbox event as failed", "event_id", event.ID.ToString(), "error", err)
+	}
+}
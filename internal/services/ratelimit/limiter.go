@@ -0,0 +1,194 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/core/logger"
+)
+
+const keyPrefix = "ratelimit"
+
+// Key selects what a rule counts requests by. IPv6 addresses are counted
+// per /64, since a single host usually owns the whole prefix. Client rules
+// count by the authenticated client and fall back to the IP for anonymous
+// calls, so a client ID the caller merely claims is never trusted.
+type Key string
+
+const (
+	KeyIP     Key = "ip"
+	KeyClient Key = "client"
+)
+
+// anyMethod makes a rule apply to every RPC.
+const anyMethod = "*"
+
+type Store interface {
+	TakeToken(ctx context.Context, key string, capacity int, refill time.Duration) (bool, time.Duration, error)
+}
+
+// Rule allows Limit requests per Period for each distinct Key value calling
+// Method, with bursts up to Limit.
+type Rule struct {
+	Method string
+	Key    Key
+	Limit  int
+	Period time.Duration
+}
+
+// Caller identifies who is making a request. ClientID is only set once the
+// caller has authenticated.
+type Caller struct {
+	IP       string
+	ClientID string
+}
+
+type Limiter struct {
+	store    Store
+	rules    map[string][]Rule
+	failOpen bool
+}
+
+func NewLimiter(store Store, rules []Rule, failOpen bool) *Limiter {
+	byMethod := make(map[string][]Rule)
+	for _, rule := range rules {
+		byMethod[rule.Method] = append(byMethod[rule.Method], rule)
+	}
+
+	return &Limiter{
+		store:    store,
+		rules:    byMethod,
+		failOpen: failOpen,
+	}
+}
+
+// WithKeys returns a limiter sharing the store that only checks rules
+// counting by one of keys. IP rules can then run before authentication,
+// which they protect, and client rules after it.
+func (l *Limiter) WithKeys(keys ...Key) *Limiter {
+	byMethod := make(map[string][]Rule, len(l.rules))
+	for method, rules := range l.rules {
+		for _, rule := range rules {
+			if slices.Contains(keys, rule.Key) {
+				byMethod[method] = append(byMethod[method], rule)
+			}
+		}
+	}
+
+	return &Limiter{
+		store:    l.store,
+		rules:    byMethod,
+		failOpen: l.failOpen,
+	}
+}
+
+// Allow checks every rule matching fullMethod and reports how long the
+// caller must wait when one of them is exhausted. Rules whose key is unknown
+// for this caller, such as a missing IP, are skipped.
+func (l *Limiter) Allow(ctx context.Context, fullMethod string, caller Caller) (bool, time.Duration, error) {
+	method := path.Base(fullMethod)
+	rules := slices.Concat(l.rules[method], l.rules[anyMethod])
+
+	for _, rule := range rules {
+		value := caller.value(rule.Key)
+		if value == "" {
+			continue
+		}
+
+		key := strings.Join([]string{keyPrefix, rule.Method, string(rule.Key), value}, ":")
+		allowed, retryAfter, err := l.store.TakeToken(ctx, key, rule.Limit, rule.Period/time.Duration(rule.Limit))
+		if err != nil {
+			if l.failOpen {
+				logger.Warn("rate limit check failed, allowing request", "method", method, "error", err)
+				continue
+			}
+			return false, 0, err
+		}
+
+		if !allowed {
+			return false, retryAfter, nil
+		}
+	}
+
+	return true, 0, nil
+}
+
+func (c Caller) value(key Key) string {
+	switch key {
+	case KeyIP:
+		return ipKey(c.IP)
+	case KeyClient:
+		if c.ClientID != "" {
+			return "id:" + c.ClientID
+		}
+		if ip := ipKey(c.IP); ip != "" {
+			return "ip:" + ip
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+func ipKey(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+
+	addr = addr.Unmap()
+	if addr.Is6() {
+		prefix, _ := addr.WithZone("").Prefix(64)
+		return prefix.String()
+	}
+
+	return addr.String()
+}
+
+// ParseRules reads rules written as method:key:limit/period, for example
+// "SendOtp:ip:10/1m". The method is the bare RPC name, or * for a budget
+// shared by all RPCs.
+func ParseRules(values []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(values))
+	for _, value := range values {
+		parts := strings.Split(strings.TrimSpace(value), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid rate limit rule %q", value)
+		}
+
+		key := Key(parts[1])
+		if key != KeyIP && key != KeyClient {
+			return nil, fmt.Errorf("invalid rate limit key in rule %q", value)
+		}
+
+		limitPart, periodPart, found := strings.Cut(parts[2], "/")
+		if !found {
+			return nil, fmt.Errorf("invalid rate limit in rule %q", value)
+		}
+
+		limit, err := strconv.Atoi(limitPart)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid rate limit in rule %q", value)
+		}
+
+		period, err := time.ParseDuration(periodPart)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("invalid rate limit period in rule %q", value)
+		}
+
+		rules = append(rules, Rule{
+			Method: parts[0],
+			Key:    key,
+			Limit:  limit,
+			Period: period,
+		})
+	}
+
+	return rules, nil
+}
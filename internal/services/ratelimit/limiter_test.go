@@ -0,0 +1,169 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/redis"
+)
+
+const sendOtpMethod = "/auth.v1.AuthService/SendOtp"
+
+func newTestLimiter(t *testing.T, rules []string, failOpen bool) (*Limiter, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	port, err := strconv.Atoi(srv.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := redis.NewClient(context.Background(), &config.Redis{Host: srv.Host(), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewLimiter(client, parsed, failOpen), srv
+}
+
+func allow(t *testing.T, l *Limiter, caller Caller) bool {
+	t.Helper()
+
+	allowed, _, err := l.Allow(context.Background(), sendOtpMethod, caller)
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	return allowed
+}
+
+func TestLimiterIP(t *testing.T) {
+	l, _ := newTestLimiter(t, []string{"SendOtp:ip:2/1m"}, false)
+	caller := Caller{IP: "203.0.113.7"}
+
+	for i := range 2 {
+		if !allow(t, l, caller) {
+			t.Fatalf("request %d rejected within the limit", i+1)
+		}
+	}
+
+	allowed, retryAfter, err := l.Allow(context.Background(), sendOtpMethod, caller)
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if allowed {
+		t.Fatal("request over the limit allowed")
+	}
+	if retryAfter <= 0 || retryAfter > 30*time.Second {
+		t.Errorf("retryAfter = %s, want within (0, 30s]", retryAfter)
+	}
+
+	if !allow(t, l, Caller{IP: "203.0.113.8"}) {
+		t.Error("another IP shares the budget")
+	}
+}
+
+// The bucket refills on the Redis clock, so replicas with skewed clocks
+// share one budget.
+func TestLimiterRefillsOnRedisClock(t *testing.T) {
+	l, srv := newTestLimiter(t, []string{"SendOtp:ip:1/1m"}, false)
+	caller := Caller{IP: "203.0.113.7"}
+
+	start := time.Now()
+	srv.SetTime(start)
+	if !allow(t, l, caller) {
+		t.Fatal("first request rejected")
+	}
+	if allow(t, l, caller) {
+		t.Fatal("request over the limit allowed")
+	}
+
+	srv.SetTime(start.Add(time.Minute))
+	if !allow(t, l, caller) {
+		t.Error("bucket did not refill once the Redis clock moved on")
+	}
+}
+
+func TestLimiterIPv6Prefix(t *testing.T) {
+	l, _ := newTestLimiter(t, []string{"SendOtp:ip:1/1m"}, false)
+
+	if !allow(t, l, Caller{IP: "2001:db8:1:2::1"}) {
+		t.Fatal("first request rejected")
+	}
+	if allow(t, l, Caller{IP: "2001:db8:1:2:ffff::9"}) {
+		t.Error("another address in the same /64 got its own budget")
+	}
+	if !allow(t, l, Caller{IP: "2001:db8:1:3::1"}) {
+		t.Error("an address in another /64 shares the budget")
+	}
+}
+
+func TestLimiterClient(t *testing.T) {
+	l, _ := newTestLimiter(t, []string{"SendOtp:client:1/1m"}, false)
+
+	if !allow(t, l, Caller{IP: "203.0.113.7", ClientID: "web"}) {
+		t.Fatal("first request rejected")
+	}
+	if allow(t, l, Caller{IP: "203.0.113.8", ClientID: "web"}) {
+		t.Error("the same client got a new budget from another IP")
+	}
+
+	// Anonymous callers are counted by IP and never share the budget of a
+	// client they could otherwise impersonate.
+	if !allow(t, l, Caller{IP: "203.0.113.7"}) {
+		t.Error("anonymous caller shares the budget of an authenticated client")
+	}
+	if allow(t, l, Caller{IP: "203.0.113.7"}) {
+		t.Error("anonymous caller was not limited by IP")
+	}
+}
+
+func TestLimiterWithKeys(t *testing.T) {
+	l, _ := newTestLimiter(t, []string{"SendOtp:ip:1/1m", "SendOtp:client:5/1m"}, false)
+	clientOnly := l.WithKeys(KeyClient)
+	caller := Caller{IP: "203.0.113.7", ClientID: "web"}
+
+	for i := range 3 {
+		if !allow(t, clientOnly, caller) {
+			t.Fatalf("request %d rejected by an IP rule", i+1)
+		}
+	}
+	if !allow(t, l.WithKeys(KeyIP), caller) {
+		t.Error("the client limiter used the IP budget")
+	}
+}
+
+func TestLimiterStoreFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		failOpen bool
+		want     bool
+		wantErr  bool
+	}{
+		{name: "fail open", failOpen: true, want: true},
+		{name: "fail closed", failOpen: false, want: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, srv := newTestLimiter(t, []string{"SendOtp:ip:1/1m"}, tt.failOpen)
+			srv.Close()
+
+			allowed, _, err := l.Allow(context.Background(), sendOtpMethod, Caller{IP: "203.0.113.7"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Allow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if allowed != tt.want {
+				t.Errorf("Allow() = %v, want %v", allowed, tt.want)
+			}
+		})
+	}
+}
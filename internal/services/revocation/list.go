@@ -0,0 +1,105 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+)
+
+const (
+	tokenKeyPrefix   = "revoked:jti:"
+	sessionKeyPrefix = "revoked:sid:"
+	subjectKeyPrefix = "revoked:sub:"
+)
+
+type Store interface {
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	MGet(ctx context.Context, keys ...string) ([]any, error)
+}
+
+// List records revoked access tokens. Single tokens are revoked by subject
+// and jti, so naming the jti of another subject's token revokes nothing;
+// sessions and subjects get a not-before time, and every token of theirs
+// issued at or before it is revoked. Each entry expires once no token it
+// covers can still be valid.
+//
+// The list fails closed: when the store cannot be reached, IsRevoked returns
+// an error and the token is not accepted, since a revoked token must not
+// come back to life while Redis is down.
+type List struct {
+	store Store
+}
+
+func NewList(store Store) *List {
+	return &List{
+		store: store,
+	}
+}
+
+func (l *List) RevokeToken(ctx context.Context, subject string, tokenID string, expiresAt time.Time) error {
+	return l.set(ctx, tokenKey(subject, tokenID), 1, expiresAt)
+}
+
+func (l *List) RevokeSession(ctx context.Context, sessionID string, notBefore time.Time, expiresAt time.Time) error {
+	return l.set(ctx, sessionKeyPrefix+sessionID, notBefore.Unix(), expiresAt)
+}
+
+func (l *List) RevokeSubject(ctx context.Context, subject string, notBefore time.Time, expiresAt time.Time) error {
+	return l.set(ctx, subjectKeyPrefix+subject, notBefore.Unix(), expiresAt)
+}
+
+func (l *List) set(ctx context.Context, key string, value any, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if err := l.store.Set(ctx, key, value, ttl); err != nil {
+		return fmt.Errorf("failed to store revocation: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked looks up every entry that may cover the token in one round trip.
+func (l *List) IsRevoked(ctx context.Context, claims *token.Claims) (bool, error) {
+	keys := []string{subjectKeyPrefix + claims.Subject}
+	if claims.SessionID != "" {
+		keys = append(keys, sessionKeyPrefix+claims.SessionID)
+	}
+	if claims.ID != "" {
+		keys = append(keys, tokenKey(claims.Subject, claims.ID))
+	}
+
+	values, err := l.store.MGet(ctx, keys...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check revocation: %w", err)
+	}
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if strings.HasPrefix(keys[i], tokenKeyPrefix) {
+			return true, nil
+		}
+
+		// iat has second precision, so a token issued in the same second as
+		// the revocation is treated as revoked.
+		notBefore, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+		if err != nil || claims.IssuedAt == nil || claims.IssuedAt.Unix() <= notBefore {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func tokenKey(subject string, tokenID string) string {
+	return tokenKeyPrefix + subject + ":" + tokenID
+}
@@ -0,0 +1,140 @@
+package revocation
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/teacinema-go/auth-service/internal/auth/token"
+	"github.com/teacinema-go/auth-service/internal/config"
+	"github.com/teacinema-go/auth-service/internal/infra/storage/redis"
+)
+
+func newTestList(t *testing.T) (*List, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	port, err := strconv.Atoi(srv.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := redis.NewClient(context.Background(), &config.Redis{Host: srv.Host(), Port: port})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewList(client), srv
+}
+
+func claimsFor(subject string, sessionID string, tokenID string, issuedAt *time.Time) *token.Claims {
+	claims := &token.Claims{
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: subject,
+			ID:      tokenID,
+		},
+	}
+	if issuedAt != nil {
+		claims.IssuedAt = jwt.NewNumericDate(*issuedAt)
+	}
+	return claims
+}
+
+func isRevoked(t *testing.T, l *List, claims *token.Claims) bool {
+	t.Helper()
+
+	revoked, err := l.IsRevoked(context.Background(), claims)
+	if err != nil {
+		t.Fatalf("IsRevoked() error = %v", err)
+	}
+	return revoked
+}
+
+func TestListNotBefore(t *testing.T) {
+	l, _ := newTestList(t)
+	ctx := context.Background()
+
+	notBefore := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	expiresAt := notBefore.Add(time.Hour)
+	if err := l.RevokeSubject(ctx, "alice", notBefore, expiresAt); err != nil {
+		t.Fatalf("RevokeSubject() error = %v", err)
+	}
+	if err := l.RevokeSession(ctx, "session-1", notBefore, expiresAt); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+
+	before := notBefore.Add(-time.Second)
+	sameSecond := notBefore.Add(400 * time.Millisecond)
+	after := notBefore.Add(time.Second)
+
+	tests := []struct {
+		name   string
+		claims *token.Claims
+		want   bool
+	}{
+		{name: "subject, issued before", claims: claimsFor("alice", "", "", &before), want: true},
+		{name: "subject, issued in the same second", claims: claimsFor("alice", "", "", &sameSecond), want: true},
+		{name: "subject, issued after", claims: claimsFor("alice", "", "", &after), want: false},
+		{name: "subject, no iat", claims: claimsFor("alice", "", "", nil), want: true},
+		{name: "session, issued before", claims: claimsFor("bob", "session-1", "", &before), want: true},
+		{name: "session, issued after", claims: claimsFor("bob", "session-1", "", &after), want: false},
+		{name: "other subject and session", claims: claimsFor("bob", "session-2", "", &before), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRevoked(t, l, tt.claims); got != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListRevokeTokenIsScopedToSubject(t *testing.T) {
+	l, srv := newTestList(t)
+	issuedAt := time.Now()
+
+	if err := l.RevokeToken(context.Background(), "alice", "jti-1", issuedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	if !isRevoked(t, l, claimsFor("alice", "", "jti-1", &issuedAt)) {
+		t.Error("revoked token is accepted")
+	}
+	if isRevoked(t, l, claimsFor("alice", "", "jti-2", &issuedAt)) {
+		t.Error("another token of the subject is revoked")
+	}
+	if isRevoked(t, l, claimsFor("bob", "", "jti-1", &issuedAt)) {
+		t.Error("a token of another subject with the same jti is revoked")
+	}
+
+	srv.FastForward(time.Minute)
+	if isRevoked(t, l, claimsFor("alice", "", "jti-1", &issuedAt)) {
+		t.Error("entry outlived its expiry")
+	}
+}
+
+func TestListSkipsExpiredEntries(t *testing.T) {
+	l, srv := newTestList(t)
+
+	if err := l.RevokeSubject(context.Background(), "alice", time.Now(), time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("RevokeSubject() error = %v", err)
+	}
+	if keys := srv.Keys(); len(keys) != 0 {
+		t.Errorf("stored %v for an entry that already expired", keys)
+	}
+}
+
+func TestListFailsClosed(t *testing.T) {
+	l, srv := newTestList(t)
+	srv.Close()
+
+	issuedAt := time.Now()
+	if _, err := l.IsRevoked(context.Background(), claimsFor("alice", "", "jti-1", &issuedAt)); err == nil {
+		t.Error("IsRevoked() did not report the unreachable store")
+	}
+}
@@ -0,0 +1,44 @@
+package txmanager
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DB routes queries to the transaction carried by ctx and falls back to the
+// pool otherwise. Passing it to sqlc.New makes every repository
+// transaction-aware without changing the repositories themselves.
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+func NewDB(pool *pgxpool.Pool) *DB {
+	return &DB{pool: pool}
+}
+
+func (d *DB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Exec(ctx, sql, args...)
+	}
+
+	return d.pool.Exec(ctx, sql, args...)
+}
+
+func (d *DB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Query(ctx, sql, args...)
+	}
+
+	return d.pool.Query(ctx, sql, args...)
+}
+
+func (d *DB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+
+	return d.pool.QueryRow(ctx, sql, args...)
+}
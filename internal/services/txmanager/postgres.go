@@ -2,17 +2,35 @@ package txmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/teacinema-go/auth-service/internal/auth/repositories/account"
-	"github.com/teacinema-go/auth-service/internal/auth/repositories/refreshToken"
 	"github.com/teacinema-go/auth-service/internal/auth/services"
-	"github.com/teacinema-go/auth-service/internal/infra/storage/postgres/sqlc"
+	"github.com/teacinema-go/auth-service/pkg/utils"
 	"github.com/teacinema-go/core/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	maxAttempts = 3
+	baseBackoff = 10 * time.Millisecond
+	maxBackoff  = 200 * time.Millisecond
+
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+type txKey struct{}
+
+var tracer = otel.Tracer("github.com/teacinema-go/auth-service/internal/services/txmanager")
+
 type PostgresTxManager struct {
 	pool *pgxpool.Pool
 }
@@ -21,48 +39,96 @@ func NewPostgresTxManager(pool *pgxpool.Pool) *PostgresTxManager {
 	return &PostgresTxManager{pool: pool}
 }
 
-func (m *PostgresTxManager) WithTransaction(ctx context.Context, fn func(repos services.TxRepositories) (any, error)) (any, error) {
-	tx, err := m.pool.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("begin transaction failed: %w", err)
+// WithTransaction joins the transaction already carried by ctx, if any, so
+// nested calls commit or roll back together with the outermost one. Only
+// the outermost call retries on serialization failures and deadlocks.
+func (m *PostgresTxManager) WithTransaction(ctx context.Context, opts services.TxOptions, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
 	}
 
-	defer func(tx pgx.Tx, ctx context.Context) {
-		err = tx.Rollback(ctx)
-		if err != nil {
-			logger.Error("rollback transaction failed", "error", err)
+	ctx, span := tracer.Start(ctx, "db.transaction",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.transaction.isolation", string(toPgxTxOptions(opts).IsoLevel)),
+			attribute.Bool("db.transaction.read_only", opts.ReadOnly),
+		),
+	)
+	defer span.End()
+
+	var err error
+	for attempt := int32(0); attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(utils.Backoff(attempt-1, baseBackoff, maxBackoff)):
+			}
 		}
-	}(tx, ctx)
 
-	repos := newTxRepositories(sqlc.New(tx))
+		err = m.run(ctx, opts, fn)
+		span.SetAttributes(attribute.Int("db.transaction.attempts", int(attempt)+1))
+		if !isRetryable(err) || attempt+1 == maxAttempts {
+			break
+		}
 
-	if res, err := fn(repos); err != nil {
-		return res, err
+		span.AddEvent("retry", trace.WithAttributes(attribute.String("error", err.Error())))
+		logger.Warn("retrying transaction", "attempt", attempt+1, "error", err)
 	}
 
-	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit transaction failed: %w", err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
 
-	return nil, nil
+	return err
 }
 
-type txRepositories struct {
-	accountRepo      services.AccountRepository
-	refreshTokenRepo services.RefreshTokenRepository
-}
+func (m *PostgresTxManager) run(ctx context.Context, opts services.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := m.pool.BeginTx(ctx, toPgxTxOptions(opts))
+	if err != nil {
+		return fmt.Errorf("begin transaction failed: %w", err)
+	}
+
+	defer func() {
+		err := tx.Rollback(context.WithoutCancel(ctx))
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Error("rollback transaction failed", "error", err)
+		}
+	}()
 
-func newTxRepositories(q sqlc.Querier) *txRepositories {
-	return &txRepositories{
-		accountRepo:      account.NewPostgresAccountRepository(q),
-		refreshTokenRepo: refreshToken.NewPostgresRefreshTokenRepository(q),
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction failed: %w", err)
 	}
+
+	return nil
 }
 
-func (r *txRepositories) Account() services.AccountRepository {
-	return r.accountRepo
+func toPgxTxOptions(opts services.TxOptions) pgx.TxOptions {
+	res := pgx.TxOptions{IsoLevel: pgx.ReadCommitted}
+	switch opts.Isolation {
+	case services.IsolationRepeatableRead:
+		res.IsoLevel = pgx.RepeatableRead
+	case services.IsolationSerializable:
+		res.IsoLevel = pgx.Serializable
+	}
+	if opts.ReadOnly {
+		res.AccessMode = pgx.ReadOnly
+	}
+
+	return res
 }
 
-func (r *txRepositories) RefreshToken() services.RefreshTokenRepository {
-	return r.refreshTokenRepo
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
 }
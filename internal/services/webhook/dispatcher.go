@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/pkg/utils"
+	"github.com/teacinema-go/core/logger"
+)
+
+const (
+	SignatureHeader  = "X-Webhook-Signature"
+	EventTypeHeader  = "X-Webhook-Event"
+	DeliveryIDHeader = "X-Webhook-Delivery"
+
+	leaseDuration        = 2 * time.Minute
+	concurrency          = 8
+	baseBackoff          = 5 * time.Second
+	maxBackoff           = time.Hour
+	maxResponseBodyBytes = 64 << 10
+	maxErrorLength       = 1000
+)
+
+type Repository interface {
+	ClaimWebhookDeliveries(ctx context.Context, lockedUntil time.Time, batchSize int32) ([]*entities.WebhookDelivery, error)
+	GetWebhookSubscriptionByID(ctx context.Context, subscriptionID valueobject.ID) (*entities.WebhookSubscription, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, deliveryID valueobject.ID, statusCode int32) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg dto.WebhookDeliveryFailure) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg dto.CreateWebhookDeliveryAttemptParams) error
+}
+
+type Dispatcher struct {
+	repo         Repository
+	client       *http.Client
+	maxAttempts  int32
+	batchSize    int32
+	pollInterval time.Duration
+}
+
+func NewDispatcher(repo Repository, client *http.Client, maxAttempts int32, batchSize int32, pollInterval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repo:         repo,
+		client:       client,
+		maxAttempts:  maxAttempts,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+	}
+}
+
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// NewHTTPClient never follows redirects: the signed payload goes only to the
+// URL an admin registered. It also refuses to connect to anything but public
+// addresses. The check runs on the address actually dialed, so a host that
+// resolves to an internal address after registration is refused too, and
+// proxies from the environment are ignored for the same reason.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !utils.IsPublicIP(addrPort.Addr()) {
+				return fmt.Errorf("%w %s", errNonPublicAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign returns the signature header value for a payload. Receivers recompute
+// the HMAC-SHA256 of "<timestamp>.<body>" with their secret and compare.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed := d.dispatchBatch(ctx)
+		if claimed >= int(d.batchSize) && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchBatch(ctx context.Context) int {
+	deliveries, err := d.repo.ClaimWebhookDeliveries(ctx, time.Now().Add(leaseDuration), d.batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("failed to claim webhook deliveries", "error", err)
+		}
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, delivery := range deliveries {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			d.deliver(ctx, delivery)
+		})
+	}
+	wg.Wait()
+
+	return len(deliveries)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *entities.WebhookDelivery) {
+	log := logger.With(
+		"delivery_id", delivery.ID.ToString(),
+		"subscription_id", delivery.SubscriptionID.ToString(),
+		"event_type", delivery.EventType,
+	)
+
+	subscription, err := d.repo.GetWebhookSubscriptionByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		if !errors.Is(err, appErrors.ErrWebhookNotFound) {
+			log.Error("failed to get webhook subscription", "error", err)
+		}
+		return
+	}
+
+	startedAt := time.Now()
+	statusCode, sendErr := d.send(ctx, subscription, delivery)
+	if ctx.Err() != nil {
+		// Shutdown interrupted the request; the lease expires and the
+		// delivery is retried without counting this attempt.
+		return
+	}
+
+	var code *int32
+	if statusCode != 0 {
+		code = &statusCode
+	}
+	errMessage := ""
+	if sendErr != nil {
+		errMessage = truncate(sendErr.Error(), maxErrorLength)
+	}
+
+	d.recordAttempt(ctx, delivery, code, errMessage, time.Since(startedAt))
+
+	if sendErr == nil {
+		if err = d.repo.MarkWebhookDeliverySucceeded(ctx, delivery.ID, statusCode); err != nil {
+			log.Error("failed to mark webhook delivery as delivered", "error", err)
+		}
+		return
+	}
+
+	failure := dto.WebhookDeliveryFailure{
+		ID:            delivery.ID,
+		Status:        valueobject.WebhookDeliveryStatusPending,
+		NextAttemptAt: time.Now().Add(utils.Backoff(delivery.Attempts, baseBackoff, maxBackoff)),
+		StatusCode:    code,
+		Error:         errMessage,
+	}
+	if delivery.Attempts+1 >= d.maxAttempts {
+		failure.Status = valueobject.WebhookDeliveryStatusDead
+		log.Warn("webhook delivery moved to dead letter", "attempts", delivery.Attempts+1, "error", sendErr)
+	} else {
+		log.Warn("webhook delivery failed", "attempts", delivery.Attempts+1, "error", sendErr)
+	}
+
+	if err = d.repo.MarkWebhookDeliveryFailed(ctx, failure); err != nil {
+		log.Error("failed to mark webhook delivery as failed", "error", err)
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription *entities.WebhookSubscription, delivery *entities.WebhookDelivery) (int32, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(DeliveryIDHeader, delivery.ID.ToString())
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now().Unix(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodyBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return int32(resp.StatusCode), fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return int32(resp.StatusCode), nil
+}
+
+func (d *Dispatcher) recordAttempt(ctx context.Context, delivery *entities.WebhookDelivery, statusCode *int32, errMessage string, duration time.Duration) {
+	attemptID, err := valueobject.NewID()
+	if err != nil {
+		logger.Error("failed to generate webhook attempt ID", "error", err)
+		return
+	}
+
+	err = d.repo.CreateWebhookDeliveryAttempt(ctx, dto.CreateWebhookDeliveryAttemptParams{
+		ID:         attemptID,
+		DeliveryID: delivery.ID,
+		StatusCode: statusCode,
+		Error:      errMessage,
+		Duration:   duration,
+	})
+	if err != nil {
+		logger.Error("failed to record webhook attempt", "delivery_id", delivery.ID.ToString(), "error", err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
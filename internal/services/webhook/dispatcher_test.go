@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+)
+
+type fakeRepository struct {
+	mu           sync.Mutex
+	subscription *entities.WebhookSubscription
+	deliveries   []*entities.WebhookDelivery
+	succeeded    []valueobject.ID
+	failures     []dto.WebhookDeliveryFailure
+	attempts     []dto.CreateWebhookDeliveryAttemptParams
+}
+
+func (r *fakeRepository) ClaimWebhookDeliveries(context.Context, time.Time, int32) ([]*entities.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := r.deliveries
+	r.deliveries = nil
+	return deliveries, nil
+}
+
+func (r *fakeRepository) GetWebhookSubscriptionByID(context.Context, valueobject.ID) (*entities.WebhookSubscription, error) {
+	return r.subscription, nil
+}
+
+func (r *fakeRepository) MarkWebhookDeliverySucceeded(_ context.Context, deliveryID valueobject.ID, _ int32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.succeeded = append(r.succeeded, deliveryID)
+	return nil
+}
+
+func (r *fakeRepository) MarkWebhookDeliveryFailed(_ context.Context, arg dto.WebhookDeliveryFailure) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failures = append(r.failures, arg)
+	return nil
+}
+
+func (r *fakeRepository) CreateWebhookDeliveryAttempt(_ context.Context, arg dto.CreateWebhookDeliveryAttemptParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts = append(r.attempts, arg)
+	return nil
+}
+
+func newTestDelivery(t *testing.T, subscriptionID valueobject.ID, attempts int32) *entities.WebhookDelivery {
+	t.Helper()
+
+	deliveryID, err := valueobject.NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &entities.WebhookDelivery{
+		ID:             deliveryID,
+		SubscriptionID: subscriptionID,
+		EventType:      "account.created",
+		Payload:        json.RawMessage(`{"id":"1"}`),
+		Status:         valueobject.WebhookDeliveryStatusPending,
+		Attempts:       attempts,
+	}
+}
+
+func newTestSubscription(t *testing.T, url string) *entities.WebhookSubscription {
+	t.Helper()
+
+	subscriptionID, err := valueobject.NewID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &entities.WebhookSubscription{
+		ID:     subscriptionID,
+		URL:    url,
+		Secret: "secret",
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	subscription := newTestSubscription(t, srv.URL)
+	delivery := newTestDelivery(t, subscription.ID, 0)
+	repo := &fakeRepository{subscription: subscription, deliveries: []*entities.WebhookDelivery{delivery}}
+
+	d := NewDispatcher(repo, srv.Client(), 3, 10, time.Second)
+	if claimed := d.dispatchBatch(context.Background()); claimed != 1 {
+		t.Fatalf("dispatchBatch() = %d, want 1", claimed)
+	}
+
+	req := <-requests
+	if string(req.body) != string(delivery.Payload) {
+		t.Errorf("body = %s, want %s", req.body, delivery.Payload)
+	}
+	if got := req.header.Get(DeliveryIDHeader); got != delivery.ID.ToString() {
+		t.Errorf("%s = %q, want %q", DeliveryIDHeader, got, delivery.ID.ToString())
+	}
+	if got := req.header.Get(EventTypeHeader); got != delivery.EventType {
+		t.Errorf("%s = %q, want %q", EventTypeHeader, got, delivery.EventType)
+	}
+
+	signature := req.header.Get(SignatureHeader)
+	timestamp, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("signature %q has no timestamp", signature)
+	}
+	if want := Sign(subscription.Secret, ts, delivery.Payload); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+
+	if len(repo.succeeded) != 1 || repo.succeeded[0] != delivery.ID {
+		t.Errorf("succeeded = %v, want [%s]", repo.succeeded, delivery.ID.ToString())
+	}
+	if len(repo.attempts) != 1 || repo.attempts[0].StatusCode == nil || *repo.attempts[0].StatusCode != http.StatusNoContent {
+		t.Errorf("attempts = %+v, want one with status %d", repo.attempts, http.StatusNoContent)
+	}
+}
+
+func TestDispatcherRetriesAndDeadLetters(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	subscription := newTestSubscription(t, srv.URL)
+	retried := newTestDelivery(t, subscription.ID, 0)
+	dead := newTestDelivery(t, subscription.ID, 2)
+	repo := &fakeRepository{subscription: subscription, deliveries: []*entities.WebhookDelivery{retried, dead}}
+
+	d := NewDispatcher(repo, srv.Client(), 3, 10, time.Second)
+	d.dispatchBatch(context.Background())
+
+	if len(repo.succeeded) != 0 {
+		t.Errorf("succeeded = %v, want none", repo.succeeded)
+	}
+
+	statuses := make(map[valueobject.ID]valueobject.WebhookDeliveryStatus)
+	for _, failure := range repo.failures {
+		statuses[failure.ID] = failure.Status
+		if failure.StatusCode == nil || *failure.StatusCode != http.StatusInternalServerError {
+			t.Errorf("failure %s status code = %v, want %d", failure.ID.ToString(), failure.StatusCode, http.StatusInternalServerError)
+		}
+	}
+	if statuses[retried.ID] != valueobject.WebhookDeliveryStatusPending {
+		t.Errorf("first failure status = %q, want pending", statuses[retried.ID])
+	}
+	if statuses[dead.ID] != valueobject.WebhookDeliveryStatusDead {
+		t.Errorf("last failure status = %q, want dead", statuses[dead.ID])
+	}
+}
+
+func TestHTTPClientRefusesNonPublicAddresses(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	resp, err := NewHTTPClient(time.Second).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("request to a loopback address succeeded")
+	}
+	if !errors.Is(err, errNonPublicAddress) {
+		t.Fatalf("error = %v, want %v", err, errNonPublicAddress)
+	}
+}
@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"net/http"
+	"time"
+
+	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
+)
+
+type accountResponse struct {
+	ID        string    `json:"id"`
+	Phone     string    `json:"phone,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (g *Gateway) getAccount(w http.ResponseWriter, r *http.Request) {
+	resp, err := invoke(g, r, accountv1.AccountService_GetAccount_FullMethodName, &accountv1.GetAccountRequest{
+		Id: r.PathValue("id"),
+	}, g.accountServer.GetAccount)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if !resp.Success {
+		statusCode := http.StatusInternalServerError
+		switch resp.ErrorCode {
+		case accountv1.GetAccountResponse_INVALID_ID:
+			statusCode = http.StatusBadRequest
+		case accountv1.GetAccountResponse_ACCOUNT_NOT_FOUND:
+			statusCode = http.StatusNotFound
+		}
+		writeError(w, statusCode, resp.ErrorCode.String(), resp.ErrorMessage)
+		return
+	}
+
+	acc := resp.Account
+	writeJSON(w, http.StatusOK, accountResponse{
+		ID:        acc.Id,
+		Phone:     acc.Phone,
+		Email:     acc.Email,
+		Role:      roleFromProto(acc.Role),
+		CreatedAt: acc.CreatedAt.AsTime(),
+		UpdatedAt: acc.UpdatedAt.AsTime(),
+	})
+}
+
+func roleFromProto(role accountv1.Role) string {
+	switch role {
+	case accountv1.Role_USER:
+		return "user"
+	case accountv1.Role_ADMIN:
+		return "admin"
+	default:
+		return "unspecified"
+	}
+}
@@ -0,0 +1,264 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type identifierRequest struct {
+	Identifier     string `json:"identifier"`
+	IdentifierType string `json:"identifier_type"`
+}
+
+type sendOtpRequest struct {
+	identifierRequest
+	ChallengeType     string `json:"challenge_type"`
+	ChallengeResponse string `json:"challenge_response"`
+}
+
+type sendOtpResponse struct {
+	ExpiresInSeconds int32 `json:"expires_in_seconds"`
+}
+
+type verifyOtpRequest struct {
+	identifierRequest
+	Otp string `json:"otp"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokensResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	TokenType        string `json:"token_type"`
+	ExpiresInSeconds int32  `json:"expires_in_seconds"`
+	CSRFToken        string `json:"csrf_token,omitempty"`
+}
+
+func (g *Gateway) sendOtp(w http.ResponseWriter, r *http.Request) {
+	var body sendOtpRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	resp, err := invoke(g, r, authv1.AuthService_SendOtp_FullMethodName, &authv1.SendOtpRequest{
+		Identifier:        body.Identifier,
+		IdentifierType:    valueobject.IdentifierType(body.IdentifierType).ToProto(),
+		ChallengeType:     valueobject.ChallengeType(body.ChallengeType).ToProto(),
+		ChallengeResponse: body.ChallengeResponse,
+	}, g.authServer.SendOtp)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if !resp.Success {
+		statusCode := http.StatusInternalServerError
+		switch resp.ErrorCode {
+		case authv1.SendOtpResponse_INVALID_IDENTIFIER_TYPE, authv1.SendOtpResponse_INVALID_IDENTIFIER,
+			authv1.SendOtpResponse_PHONE_NOT_ALLOWED, authv1.SendOtpResponse_EMAIL_NOT_ALLOWED:
+			statusCode = http.StatusBadRequest
+		case authv1.SendOtpResponse_ACCOUNT_ALREADY_EXISTS:
+			statusCode = http.StatusConflict
+		case authv1.SendOtpResponse_CHALLENGE_REQUIRED:
+			statusCode = http.StatusPreconditionRequired
+		case authv1.SendOtpResponse_INVALID_CHALLENGE:
+			statusCode = http.StatusForbidden
+		}
+		writeErrorWithMetadata(w, statusCode, resp.ErrorCode.String(), resp.ErrorMessage, challengeMetadata(resp.Challenge))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sendOtpResponse{
+		ExpiresInSeconds: resp.OtpInfo.ExpiresInSeconds,
+	})
+}
+
+// challengeMetadata flattens a challenge into the same keys the ErrorInfo
+// metadata uses, so browser clients see one shape in either error mode.
+func challengeMetadata(challenge *authv1.SendOtpResponse_Challenge) map[string]string {
+	if challenge == nil {
+		return nil
+	}
+
+	types := make([]string, 0, len(challenge.Types))
+	for _, t := range challenge.Types {
+		types = append(types, string(valueobject.NewChallengeTypeFromProto(t)))
+	}
+
+	metadata := map[string]string{
+		"challenge_types": strings.Join(types, ","),
+	}
+	if challenge.CaptchaProvider != "" {
+		metadata["captcha_provider"] = challenge.CaptchaProvider
+	}
+	if challenge.PowChallenge != "" {
+		metadata["pow_challenge"] = challenge.PowChallenge
+		metadata["pow_difficulty"] = strconv.Itoa(int(challenge.PowDifficulty))
+	}
+
+	return metadata
+}
+
+func (g *Gateway) verifyOtp(w http.ResponseWriter, r *http.Request) {
+	var body verifyOtpRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+
+	resp, err := invoke(g, r, authv1.AuthService_VerifyOtp_FullMethodName, &authv1.VerifyOtpRequest{
+		Otp:            body.Otp,
+		Identifier:     body.Identifier,
+		IdentifierType: valueobject.IdentifierType(body.IdentifierType).ToProto(),
+	}, g.authServer.VerifyOtp)
+	if err != nil {
+		writeStatusError(w, err)
+		return
+	}
+
+	if !resp.Success {
+		statusCode := http.StatusInternalServerError
+		switch resp.ErrorCode {
+		case authv1.VerifyOtpResponse_INVALID_IDENTIFIER_TYPE, authv1.VerifyOtpResponse_INVALID_IDENTIFIER:
+			statusCode = http.StatusBadRequest
+		case authv1.VerifyOtpResponse_INVALID_OTP, authv1.VerifyOtpResponse_EXPIRED_OTP:
+			statusCode = http.StatusUnauthorized
+		case authv1.VerifyOtpResponse_ACCOUNT_ALREADY_EXISTS:
+			statusCode = http.StatusConflict
+		}
+		writeError(w, statusCode, resp.ErrorCode.String(), resp.ErrorMessage)
+		return
+	}
+
+	g.writeTokens(w, resp.Tokens.AccessToken, resp.Tokens.RefreshToken, resp.Tokens.ExpiresInSeconds, resp.Tokens.RefreshExpiresInSeconds)
+}
+
+func (g *Gateway) refresh(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := g.readRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := invoke(g, r, authv1.AuthService_Refresh_FullMethodName, &authv1.RefreshRequest{
+		RefreshToken: refreshToken,
+	}, g.authServer.Refresh)
+	if err != nil {
+		g.clearSessionIfUnauthenticated(w, err)
+		writeStatusError(w, err)
+		return
+	}
+
+	if !resp.Success {
+		statusCode := http.StatusInternalServerError
+		switch resp.ErrorCode {
+		case authv1.RefreshResponse_INVALID_REFRESH_TOKEN, authv1.RefreshResponse_EXPIRED_REFRESH_TOKEN:
+			statusCode = http.StatusUnauthorized
+			g.clearSession(w)
+		}
+		writeError(w, statusCode, resp.ErrorCode.String(), resp.ErrorMessage)
+		return
+	}
+
+	g.writeTokens(w, resp.Tokens.AccessToken, resp.Tokens.RefreshToken, resp.Tokens.ExpiresInSeconds, resp.Tokens.RefreshExpiresInSeconds)
+}
+
+func (g *Gateway) logout(w http.ResponseWriter, r *http.Request) {
+	refreshToken, ok := g.readRefreshToken(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := invoke(g, r, authv1.AuthService_Logout_FullMethodName, &authv1.LogoutRequest{
+		RefreshToken: refreshToken,
+	}, g.authServer.Logout)
+	if err != nil {
+		g.clearSessionIfUnauthenticated(w, err)
+		writeStatusError(w, err)
+		return
+	}
+
+	if !resp.Success {
+		statusCode := http.StatusInternalServerError
+		if resp.ErrorCode == authv1.LogoutResponse_INVALID_REFRESH_TOKEN {
+			statusCode = http.StatusUnauthorized
+			g.clearSession(w)
+		}
+		writeError(w, statusCode, resp.ErrorCode.String(), resp.ErrorMessage)
+		return
+	}
+
+	g.clearSession(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readRefreshToken takes the refresh token from the session cookie in
+// browser mode and from the JSON body otherwise.
+func (g *Gateway) readRefreshToken(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if g.session.Enabled {
+		cookie, err := r.Cookie(refreshTokenCookie)
+		if err != nil || cookie.Value == "" {
+			writeError(w, http.StatusUnauthorized, "MISSING_REFRESH_TOKEN", "refresh token cookie is missing")
+			return "", false
+		}
+		return cookie.Value, true
+	}
+
+	var body refreshTokenRequest
+	if !decodeJSON(w, r, &body) {
+		return "", false
+	}
+
+	return body.RefreshToken, true
+}
+
+// writeTokens gives both session cookies the lifetime of the refresh token,
+// which depends on the client. Responses carrying tokens are never cached.
+func (g *Gateway) writeTokens(w http.ResponseWriter, accessToken, refreshToken string, expiresInSeconds, refreshExpiresInSeconds int32) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	res := tokensResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresInSeconds: expiresInSeconds,
+	}
+
+	if g.session.Enabled {
+		maxAge := int(refreshExpiresInSeconds)
+		csrfToken, err := g.session.setCSRFToken(w, maxAge)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to generate csrf token")
+			return
+		}
+		g.session.setRefreshToken(w, refreshToken, maxAge)
+		res.RefreshToken = ""
+		res.CSRFToken = csrfToken
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (g *Gateway) clearSession(w http.ResponseWriter) {
+	if g.session.Enabled {
+		g.session.clearRefreshToken(w)
+		g.session.clearCSRFToken(w)
+	}
+}
+
+// clearSessionIfUnauthenticated drops a refresh cookie the service has
+// rejected, so the browser stops sending it. Outside APP_ERROR_COMPAT mode
+// an invalid or expired refresh token arrives as an Unauthenticated status
+// rather than a response error code.
+func (g *Gateway) clearSessionIfUnauthenticated(w http.ResponseWriter, err error) {
+	if status.Code(err) == codes.Unauthenticated {
+		g.clearSession(w)
+	}
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name            string
+		cfg             CORSConfig
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{
+			name:            "listed origin with credentials",
+			cfg:             CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: "true",
+		},
+		{
+			name:   "unlisted origin",
+			cfg:    CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			origin: "https://evil.example.com",
+		},
+		{
+			name:       "wildcard never sends credentials",
+			cfg:        CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:     "https://evil.example.com",
+			wantOrigin: "*",
+		},
+		{
+			name:   "no origins configured",
+			cfg:    CORSConfig{},
+			origin: "https://app.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", nil)
+			r.Header.Set("Origin", tt.origin)
+
+			withCORS(tt.cfg, next).ServeHTTP(rec, r)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedHeaders: []string{"Content-Type", "X-CSRF-Token"},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("preflight reached the handler")
+	})
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/v1/auth/refresh", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	withCORS(cfg, next).ServeHTTP(rec, r)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, X-CSRF-Token" {
+		t.Errorf("Access-Control-Allow-Headers = %q", got)
+	}
+}
@@ -0,0 +1,101 @@
+package gateway
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	"github.com/teacinema-go/core/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code     string            `json:"code"`
+	Message  string            `json:"message,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("failed to encode gateway response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJSON(w, statusCode, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
+
+func writeErrorWithMetadata(w http.ResponseWriter, statusCode int, code, message string, metadata map[string]string) {
+	writeJSON(w, statusCode, errorBody{Error: errorDetail{Code: code, Message: message, Metadata: metadata}})
+}
+
+// writeStatusError translates an error returned by the interceptor chain,
+// such as a failed authentication, into the matching HTTP response. The
+// ErrorInfo reason, when present, is used as the error code.
+func writeStatusError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	if st.Code() == codes.Internal || st.Code() == codes.Unknown {
+		logger.Error("gateway call failed", "error", err)
+	}
+
+	code := rpcerror.Reason(err)
+	if code == "" {
+		code = st.Code().String()
+	}
+	if retryDelay := rpcerror.RetryDelay(err); retryDelay > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryDelay.Seconds()))))
+	}
+
+	statusCode := httpStatusFromCode(st.Code())
+	if code == "CHALLENGE_REQUIRED" {
+		statusCode = http.StatusPreconditionRequired
+	}
+
+	writeErrorWithMetadata(w, statusCode, code, st.Message(), rpcerror.ErrorMetadata(err))
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_JSON", err.Error())
+		return false
+	}
+
+	return true
+}
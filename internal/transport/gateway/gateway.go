@@ -0,0 +1,160 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	_ "embed"
+
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
+	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const maxBodyBytes = 1 << 16
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// forwardedHeaders are copied into incoming gRPC metadata so the
+// interceptors and handlers see the same request as over gRPC.
+var forwardedHeaders = map[string]string{
+	"Authorization": "authorization",
+	"X-Client-Id":   "x-client-id",
+	"User-Agent":    "user-agent",
+	"X-Request-Id":  requestinfo.RequestIDHeader,
+}
+
+// Gateway serves a JSON API by calling the gRPC handlers in-process through
+// the server's interceptor chain, so authentication, auditing and metrics
+// behave exactly as they do for gRPC clients.
+type Gateway struct {
+	authServer     authv1.AuthServiceServer
+	accountServer  accountv1.AccountServiceServer
+	interceptor    grpc.UnaryServerInterceptor
+	cors           CORSConfig
+	session        SessionConfig
+	trustedProxies []netip.Prefix
+}
+
+func New(authServer authv1.AuthServiceServer, accountServer accountv1.AccountServiceServer, interceptor grpc.UnaryServerInterceptor, cors CORSConfig, session SessionConfig, trustedProxies []netip.Prefix) *Gateway {
+	return &Gateway{
+		authServer:     authServer,
+		accountServer:  accountServer,
+		interceptor:    interceptor,
+		cors:           cors,
+		session:        session,
+		trustedProxies: trustedProxies,
+	}
+}
+
+func (g *Gateway) Handler() http.Handler {
+	protect := func(h http.HandlerFunc) http.HandlerFunc { return h }
+	mux := http.NewServeMux()
+	if g.session.Enabled {
+		protect = requireCSRF
+		mux.HandleFunc("GET /v1/auth/csrf", g.csrfToken)
+	}
+
+	mux.HandleFunc("POST /v1/auth/otp/send", protect(g.sendOtp))
+	mux.HandleFunc("POST /v1/auth/otp/verify", protect(g.verifyOtp))
+	mux.HandleFunc("POST /v1/auth/refresh", protect(g.refresh))
+	mux.HandleFunc("POST /v1/auth/logout", protect(g.logout))
+	mux.HandleFunc("GET /v1/accounts/{id}", g.getAccount)
+	mux.HandleFunc("GET /openapi.yaml", serveOpenAPI)
+
+	return withCORS(g.cors, withRequestID(mux))
+}
+
+// withRequestID settles the request ID before the call is forwarded, so the
+// same ID reaches the interceptors and is echoed in the HTTP response.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := requestinfo.RequestIDOrNew(r.Header.Get("X-Request-Id"))
+		r.Header.Set("X-Request-Id", requestID)
+		w.Header().Set("X-Request-Id", requestID)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func invoke[Req any, Resp any](g *Gateway, r *http.Request, method string, req Req, handler func(context.Context, Req) (Resp, error)) (Resp, error) {
+	var zero Resp
+
+	resp, err := g.interceptor(g.incomingContext(r), req, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return handler(ctx, req.(Req))
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	return resp.(Resp), nil
+}
+
+func (g *Gateway) incomingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for header, key := range forwardedHeaders {
+		if value := r.Header.Get(header); value != "" {
+			md.Set(key, value)
+		}
+	}
+
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+	if addrPort, ok := g.clientAddr(r); ok {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: net.TCPAddrFromAddrPort(addrPort)})
+	}
+
+	return ctx
+}
+
+// clientAddr returns the address of the client behind any trusted proxies.
+// X-Forwarded-For is only read when the connection comes from a trusted
+// proxy, and from the right: the first hop that is not a trusted proxy is
+// the client, as everything left of it may have been sent by the client
+// itself.
+func (g *Gateway) clientAddr(r *http.Request) (netip.AddrPort, bool) {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.AddrPort{}, false
+	}
+	if !g.isTrustedProxy(addrPort.Addr()) {
+		return addrPort, true
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		addrPort = netip.AddrPortFrom(addr.Unmap(), 0)
+		if !g.isTrustedProxy(addrPort.Addr()) {
+			break
+		}
+	}
+
+	return addrPort, true
+}
+
+func (g *Gateway) isTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"google.golang.org/grpc/peer"
+)
+
+func TestIncomingContextClientAddr(t *testing.T) {
+	g := &Gateway{trustedProxies: []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("fd00::/8"),
+	}}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		wantClient   string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "203.0.113.7:51234",
+			wantClient: "203.0.113.7",
+		},
+		{
+			name:         "untrusted peer cannot spoof",
+			remoteAddr:   "203.0.113.7:51234",
+			forwardedFor: []string{"198.51.100.1"},
+			wantClient:   "203.0.113.7",
+		},
+		{
+			name:         "single trusted proxy",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1"},
+			wantClient:   "198.51.100.1",
+		},
+		{
+			name:         "spoofed hops left of the client are ignored",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.1", "10.0.0.3"},
+			wantClient:   "198.51.100.1",
+		},
+		{
+			name:         "ipv6 proxy",
+			remoteAddr:   "[fd00::2]:443",
+			forwardedFor: []string{"2001:db8::1"},
+			wantClient:   "2001:db8::1",
+		},
+		{
+			name:         "malformed hop stops at the last trusted one",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1, not-an-ip"},
+			wantClient:   "10.0.0.2",
+		},
+		{
+			name:       "trusted proxy without header",
+			remoteAddr: "10.0.0.2:443",
+			wantClient: "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/v1/auth/otp/send", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			p, ok := peer.FromContext(g.incomingContext(r))
+			if !ok {
+				t.Fatal("no peer in context")
+			}
+
+			got, err := netip.ParseAddrPort(p.Addr.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Addr().String() != tt.wantClient {
+				t.Errorf("client addr = %s, want %s", got.Addr(), tt.wantClient)
+			}
+		})
+	}
+}
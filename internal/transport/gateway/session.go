@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	refreshTokenCookie = "refresh_token"
+	csrfTokenCookie    = "csrf_token"
+	csrfTokenHeader    = "X-CSRF-Token"
+
+	// refreshCookiePath covers both Refresh and Logout, the only endpoints
+	// that need to read the refresh token.
+	refreshCookiePath = "/v1/auth"
+)
+
+// SessionConfig enables browser mode: the refresh token lives in an
+// HttpOnly cookie instead of the response body, and state-changing
+// requests must echo the CSRF cookie in the X-CSRF-Token header.
+type SessionConfig struct {
+	Enabled      bool
+	CookieDomain string
+	SameSite     string
+}
+
+func (c SessionConfig) sameSite() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+func (c SessionConfig) cookie(name, value, path string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: c.sameSite(),
+	}
+}
+
+func (c SessionConfig) setRefreshToken(w http.ResponseWriter, token string, maxAge int) {
+	http.SetCookie(w, c.cookie(refreshTokenCookie, token, refreshCookiePath, maxAge, true))
+}
+
+func (c SessionConfig) clearRefreshToken(w http.ResponseWriter) {
+	http.SetCookie(w, c.cookie(refreshTokenCookie, "", refreshCookiePath, -1, true))
+}
+
+func (c SessionConfig) clearCSRFToken(w http.ResponseWriter) {
+	http.SetCookie(w, c.cookie(csrfTokenCookie, "", "/", -1, false))
+}
+
+// setCSRFToken sets a session cookie when maxAge is 0.
+func (c SessionConfig) setCSRFToken(w http.ResponseWriter, maxAge int) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	// Readable by scripts on purpose: the client copies it into the header.
+	http.SetCookie(w, c.cookie(csrfTokenCookie, token, "/", maxAge, false))
+
+	return token, nil
+}
+
+type csrfTokenResponse struct {
+	CSRFToken string `json:"csrf_token"`
+}
+
+// csrfToken hands out a token for a page that has no session yet. It lasts
+// for the browser session; signing in replaces it with one that expires
+// with the refresh token.
+func (g *Gateway) csrfToken(w http.ResponseWriter, r *http.Request) {
+	if !g.csrfOriginAllowed(r) {
+		writeError(w, http.StatusForbidden, "ORIGIN_NOT_ALLOWED", "origin is not allowed to request a csrf token")
+		return
+	}
+
+	token, err := g.session.setCSRFToken(w, 0)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to generate csrf token")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, csrfTokenResponse{CSRFToken: token})
+}
+
+// csrfOriginAllowed only serves the token to same-origin pages, which the
+// browser marks with Sec-Fetch-Site, and to origins listed explicitly in
+// the CORS config. A wildcard origin never qualifies.
+func (g *Gateway) csrfOriginAllowed(r *http.Request) bool {
+	if r.Header.Get("Sec-Fetch-Site") == "same-origin" {
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	return origin != "" && origin != "*" && slices.Contains(g.cors.AllowedOrigins, origin)
+}
+
+// requireCSRF implements the double-submit check: the header must match the
+// cookie, which a cross-site page can neither read nor set.
+func requireCSRF(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(csrfTokenCookie)
+		header := r.Header.Get(csrfTokenHeader)
+		if err != nil || cookie.Value == "" || header == "" ||
+			subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+			writeError(w, http.StatusForbidden, "INVALID_CSRF_TOKEN", "missing or mismatched csrf token")
+			return
+		}
+
+		next(w, r)
+	}
+}
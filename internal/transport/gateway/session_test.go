@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCSRFTokenOrigin(t *testing.T) {
+	g := &Gateway{
+		cors:    CORSConfig{AllowedOrigins: []string{"https://app.example.com"}},
+		session: SessionConfig{Enabled: true},
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "listed origin", headers: map[string]string{"Origin": "https://app.example.com"}, want: http.StatusOK},
+		{name: "same origin", headers: map[string]string{"Sec-Fetch-Site": "same-origin"}, want: http.StatusOK},
+		{name: "unlisted origin", headers: map[string]string{"Origin": "https://evil.example.com"}, want: http.StatusForbidden},
+		{name: "cross site without origin", headers: map[string]string{"Sec-Fetch-Site": "cross-site"}, want: http.StatusForbidden},
+		{name: "no headers", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/auth/csrf", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+
+			g.csrfToken(rec, r)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			wantCookie := tt.want == http.StatusOK
+			if got := hasCookie(rec, csrfTokenCookie); got != wantCookie {
+				t.Errorf("csrf cookie set = %v, want %v", got, wantCookie)
+			}
+		})
+	}
+}
+
+func TestCSRFTokenWildcardOrigin(t *testing.T) {
+	g := &Gateway{
+		cors:    CORSConfig{AllowedOrigins: []string{"*"}},
+		session: SessionConfig{Enabled: true},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/auth/csrf", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	rec := httptest.NewRecorder()
+
+	g.csrfToken(rec, r)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestWriteTokensCookieMaxAge(t *testing.T) {
+	g := &Gateway{session: SessionConfig{Enabled: true}}
+	rec := httptest.NewRecorder()
+
+	g.writeTokens(rec, "access", "refresh", 900, 3600)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("set %d cookies, want 2", len(cookies))
+	}
+	for _, cookie := range cookies {
+		if cookie.MaxAge != 3600 {
+			t.Errorf("%s max age = %d, want the refresh token TTL 3600", cookie.Name, cookie.MaxAge)
+		}
+	}
+}
+
+func TestWriteTokensNoStore(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		g := &Gateway{session: SessionConfig{Enabled: enabled}}
+		rec := httptest.NewRecorder()
+
+		g.writeTokens(rec, "access", "refresh", 900, 3600)
+
+		if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("session mode %v: Cache-Control = %q, want no-store", enabled, got)
+		}
+	}
+}
+
+// failingInterceptor stands in for the Errors interceptor outside
+// APP_ERROR_COMPAT mode, which reports failures as statuses.
+func failingInterceptor(err error) grpc.UnaryServerInterceptor {
+	return func(context.Context, any, *grpc.UnaryServerInfo, grpc.UnaryHandler) (any, error) {
+		return nil, err
+	}
+}
+
+func TestRefreshStatusErrorClearsSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantClear bool
+	}{
+		{name: "rejected refresh token", err: status.Error(codes.Unauthenticated, "invalid refresh token"), wantClear: true},
+		{name: "service unavailable", err: status.Error(codes.Unavailable, "unavailable"), wantClear: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Gateway{
+				authServer:  authv1.UnimplementedAuthServiceServer{},
+				interceptor: failingInterceptor(tt.err),
+				session:     SessionConfig{Enabled: true},
+			}
+
+			for _, handle := range []http.HandlerFunc{g.refresh, g.logout} {
+				r := httptest.NewRequest(http.MethodPost, "/v1/auth/refresh", nil)
+				r.AddCookie(&http.Cookie{Name: refreshTokenCookie, Value: "refresh"})
+				rec := httptest.NewRecorder()
+
+				handle(rec, r)
+
+				for _, name := range []string{refreshTokenCookie, csrfTokenCookie} {
+					if got := hasCookie(rec, name); got != tt.wantClear {
+						t.Errorf("%s cleared = %v, want %v", name, got, tt.wantClear)
+					}
+				}
+			}
+		})
+	}
+}
+
+func hasCookie(rec *httptest.ResponseRecorder, name string) bool {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -6,8 +6,9 @@ import (
 
 	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
 	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
-	"github.com/teacinema-go/core/logger"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -23,34 +24,43 @@ func NewAccountHandler(authService AuthService) *AccountHandler {
 }
 
 func (h *AccountHandler) GetAccount(ctx context.Context, req *accountv1.GetAccountRequest) (*accountv1.GetAccountResponse, error) {
-	log := logger.With(
-		"method", "GetAccount",
-	)
+	log := logging.FromContext(ctx)
 
 	log.Info("get account request received")
 
 	ID, err := valueobject.NewIDFromString(req.GetId())
 	if err != nil {
-		return sendErrorGetAccountResponse(accountv1.GetAccountResponse_INVALID_ID)
+		return sendErrorGetAccountResponse(accountv1.GetAccountResponse_INVALID_ID, err, rpcerror.Field("id"))
+	}
+
+	if err = requireAccountAccess(ctx, ID); err != nil {
+		return nil, err
 	}
 
 	acc, err := h.authService.GetAccount(ctx, ID)
 	if err != nil {
 		if errors.Is(err, appErrors.ErrAccountNotFound) {
-			return sendErrorGetAccountResponse(accountv1.GetAccountResponse_ACCOUNT_NOT_FOUND)
+			return sendErrorGetAccountResponse(accountv1.GetAccountResponse_ACCOUNT_NOT_FOUND, err)
 		}
-		return sendErrorGetAccountResponse(accountv1.GetAccountResponse_INTERNAL_ERROR)
+		log.Error("failed at GetAccount()", "error", err)
+		return sendErrorGetAccountResponse(accountv1.GetAccountResponse_INTERNAL_ERROR, err)
+	}
+
+	account := &accountv1.Account{
+		Id:        acc.ID.ToString(),
+		Role:      acc.Role.ToProto(),
+		CreatedAt: timestamppb.New(acc.CreatedAt),
+		UpdatedAt: timestamppb.New(acc.UpdatedAt),
+	}
+	if acc.Phone != nil {
+		account.Phone = *acc.Phone
+	}
+	if acc.Email != nil {
+		account.Email = *acc.Email
 	}
 
 	return &accountv1.GetAccountResponse{
 		Success: true,
-		Account: &accountv1.Account{
-			Id:        acc.ID.ToString(),
-			Phone:     *acc.Phone,
-			Email:     *acc.Email,
-			Role:      acc.Role.ToProto(),
-			CreatedAt: timestamppb.New(acc.CreatedAt),
-			UpdatedAt: timestamppb.New(acc.UpdatedAt),
-		},
+		Account: account,
 	}, nil
 }
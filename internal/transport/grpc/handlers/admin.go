@@ -0,0 +1,450 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	adminv1 "github.com/teacinema-go/contracts/gen/go/admin/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AdminHandler struct {
+	authService AuthService
+	adminv1.UnimplementedAdminServiceServer
+}
+
+func NewAdminHandler(authService AuthService) *AdminHandler {
+	return &AdminHandler{
+		authService: authService,
+	}
+}
+
+func (h *AdminHandler) ListRoles(ctx context.Context, _ *adminv1.ListRolesRequest) (*adminv1.ListRolesResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list roles request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesRead); err != nil {
+		return nil, err
+	}
+
+	roles, err := h.authService.ListRoles(ctx)
+	if err != nil {
+		log.Error("failed at ListRoles()", "error", err)
+		return fail(&adminv1.ListRolesResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListRolesResponse_INTERNAL_ERROR,
+		}, err)
+	}
+
+	res := make([]*adminv1.Role, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, string(permission))
+		}
+		res = append(res, &adminv1.Role{
+			Name:        string(role.Name),
+			Description: role.Description,
+			Permissions: permissions,
+			CreatedAt:   timestamppb.New(role.CreatedAt),
+		})
+	}
+
+	return &adminv1.ListRolesResponse{
+		Success: true,
+		Roles:   res,
+	}, nil
+}
+
+func (h *AdminHandler) ListPermissions(ctx context.Context, _ *adminv1.ListPermissionsRequest) (*adminv1.ListPermissionsResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list permissions request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesRead); err != nil {
+		return nil, err
+	}
+
+	permissions, err := h.authService.ListPermissions(ctx)
+	if err != nil {
+		log.Error("failed at ListPermissions()", "error", err)
+		return fail(&adminv1.ListPermissionsResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListPermissionsResponse_INTERNAL_ERROR,
+		}, err)
+	}
+
+	res := make([]*adminv1.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		res = append(res, &adminv1.Permission{
+			Name:        string(permission.Name),
+			Description: permission.Description,
+		})
+	}
+
+	return &adminv1.ListPermissionsResponse{
+		Success:     true,
+		Permissions: res,
+	}, nil
+}
+
+func (h *AdminHandler) CreateRole(ctx context.Context, req *adminv1.CreateRoleRequest) (*adminv1.CreateRoleResponse, error) {
+	log := logging.FromContext(ctx).With("role", req.Name)
+
+	log.Info("create role request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesWrite); err != nil {
+		return nil, err
+	}
+
+	err := h.authService.CreateRole(ctx, valueobject.Role(req.Name), req.Description, toPermissions(req.Permissions))
+	if err != nil {
+		errorCode := adminv1.CreateRoleResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidRole):
+			errorCode = adminv1.CreateRoleResponse_INVALID_NAME
+		case errors.Is(err, appErrors.ErrInvalidPermission):
+			errorCode = adminv1.CreateRoleResponse_INVALID_PERMISSION
+		case errors.Is(err, appErrors.ErrRoleAlreadyExists):
+			errorCode = adminv1.CreateRoleResponse_ROLE_ALREADY_EXISTS
+		default:
+			log.Error("failed at CreateRole()", "error", err)
+		}
+		return fail(&adminv1.CreateRoleResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("role created")
+
+	return &adminv1.CreateRoleResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) DeleteRole(ctx context.Context, req *adminv1.DeleteRoleRequest) (*adminv1.DeleteRoleResponse, error) {
+	log := logging.FromContext(ctx).With("role", req.Name)
+
+	log.Info("delete role request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesWrite); err != nil {
+		return nil, err
+	}
+
+	err := h.authService.DeleteRole(ctx, valueobject.Role(req.Name))
+	if err != nil {
+		errorCode := adminv1.DeleteRoleResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrRoleNotFound):
+			errorCode = adminv1.DeleteRoleResponse_ROLE_NOT_FOUND
+		case errors.Is(err, appErrors.ErrRoleInUse):
+			errorCode = adminv1.DeleteRoleResponse_ROLE_IN_USE
+		case errors.Is(err, appErrors.ErrBuiltinRole):
+			errorCode = adminv1.DeleteRoleResponse_BUILTIN_ROLE
+		default:
+			log.Error("failed at DeleteRole()", "error", err)
+		}
+		return fail(&adminv1.DeleteRoleResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("role deleted")
+
+	return &adminv1.DeleteRoleResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) SetRolePermissions(ctx context.Context, req *adminv1.SetRolePermissionsRequest) (*adminv1.SetRolePermissionsResponse, error) {
+	log := logging.FromContext(ctx).With("role", req.Role)
+
+	log.Info("set role permissions request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesWrite); err != nil {
+		return nil, err
+	}
+
+	err := h.authService.SetRolePermissions(ctx, principalAccountID(ctx), valueobject.Role(req.Role), toPermissions(req.Permissions))
+	if err != nil {
+		errorCode := adminv1.SetRolePermissionsResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrRoleNotFound):
+			errorCode = adminv1.SetRolePermissionsResponse_ROLE_NOT_FOUND
+		case errors.Is(err, appErrors.ErrInvalidPermission), errors.Is(err, appErrors.ErrRoleLockout):
+			errorCode = adminv1.SetRolePermissionsResponse_INVALID_PERMISSION
+		default:
+			log.Error("failed at SetRolePermissions()", "error", err)
+		}
+		return fail(&adminv1.SetRolePermissionsResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("role permissions updated")
+
+	return &adminv1.SetRolePermissionsResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) SetAccountRole(ctx context.Context, req *adminv1.SetAccountRoleRequest) (*adminv1.SetAccountRoleResponse, error) {
+	log := logging.FromContext(ctx).With("role", req.Role)
+
+	log.Info("set account role request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionRolesWrite); err != nil {
+		return nil, err
+	}
+
+	accountID, err := valueobject.NewIDFromString(req.AccountId)
+	if err != nil {
+		return fail(&adminv1.SetAccountRoleResponse{
+			Success:   false,
+			ErrorCode: adminv1.SetAccountRoleResponse_INVALID_ID,
+		}, err, rpcerror.Field("account_id"))
+	}
+
+	log = log.With("account_id", accountID.ToString())
+
+	err = h.authService.SetAccountRole(ctx, principalAccountID(ctx), accountID, valueobject.Role(req.Role))
+	if err != nil {
+		errorCode := adminv1.SetAccountRoleResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrAccountNotFound):
+			errorCode = adminv1.SetAccountRoleResponse_ACCOUNT_NOT_FOUND
+		case errors.Is(err, appErrors.ErrRoleNotFound):
+			errorCode = adminv1.SetAccountRoleResponse_ROLE_NOT_FOUND
+		case errors.Is(err, appErrors.ErrRoleLockout):
+			// No response code fits; the status carries ROLE_LOCKOUT.
+		default:
+			log.Error("failed at SetAccountRole()", "error", err)
+		}
+		return fail(&adminv1.SetAccountRoleResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("account role updated")
+
+	return &adminv1.SetAccountRoleResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) Impersonate(ctx context.Context, req *adminv1.ImpersonateRequest) (*adminv1.ImpersonateResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("impersonate request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionImpersonate); err != nil {
+		return nil, err
+	}
+
+	actor, err := sessionPrincipalFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := valueobject.NewIDFromString(req.AccountId)
+	if err != nil {
+		return fail(&adminv1.ImpersonateResponse{
+			Success:   false,
+			ErrorCode: adminv1.ImpersonateResponse_INVALID_ID,
+		}, err, rpcerror.Field("account_id"))
+	}
+
+	log = log.With("actor_id", actor.AccountID.ToString(), "account_id", targetID.ToString())
+
+	res, err := h.authService.Impersonate(ctx, *actor.AccountID, actor.ClientID, targetID, req.Reason)
+	if err != nil {
+		errorCode := adminv1.ImpersonateResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidReason):
+			errorCode = adminv1.ImpersonateResponse_INVALID_REASON
+		case errors.Is(err, appErrors.ErrAccountNotFound):
+			errorCode = adminv1.ImpersonateResponse_ACCOUNT_NOT_FOUND
+		case errors.Is(err, appErrors.ErrInvalidImpersonation):
+			errorCode = adminv1.ImpersonateResponse_INVALID_TARGET
+		default:
+			log.Error("failed at Impersonate()", "error", err)
+		}
+		return fail(&adminv1.ImpersonateResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("impersonation token issued")
+
+	return &adminv1.ImpersonateResponse{
+		Success:          true,
+		AccessToken:      res.AccessToken,
+		ExpiresInSeconds: res.ExpiresIn,
+	}, nil
+}
+
+// RevokeAccessTokens revokes a single access token when TokenId is set, and
+// otherwise every token issued to the account so far.
+func (h *AdminHandler) RevokeAccessTokens(ctx context.Context, req *adminv1.RevokeAccessTokensRequest) (*adminv1.RevokeAccessTokensResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("revoke access tokens request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionSessionsRevoke); err != nil {
+		return nil, err
+	}
+
+	accountID, err := valueobject.NewIDFromString(req.AccountId)
+	if err != nil {
+		return fail(&adminv1.RevokeAccessTokensResponse{
+			Success:   false,
+			ErrorCode: adminv1.RevokeAccessTokensResponse_INVALID_ID,
+		}, err, rpcerror.Field("account_id"))
+	}
+
+	log = log.With("account_id", accountID.ToString())
+
+	if req.TokenId != "" {
+		tokenID, err := valueobject.NewIDFromString(req.TokenId)
+		if err != nil {
+			return fail(&adminv1.RevokeAccessTokensResponse{
+				Success:   false,
+				ErrorCode: adminv1.RevokeAccessTokensResponse_INVALID_ID,
+			}, err, rpcerror.Field("token_id"))
+		}
+
+		log = log.With("token_id", tokenID.ToString())
+		err = h.authService.RevokeAccessToken(ctx, accountID, tokenID)
+	} else {
+		err = h.authService.RevokeAccountTokens(ctx, accountID)
+	}
+	if err != nil {
+		errorCode := adminv1.RevokeAccessTokensResponse_INTERNAL_ERROR
+		if errors.Is(err, appErrors.ErrAccountNotFound) {
+			errorCode = adminv1.RevokeAccessTokensResponse_ACCOUNT_NOT_FOUND
+		} else {
+			log.Error("failed at RevokeAccessTokens()", "error", err)
+		}
+		return fail(&adminv1.RevokeAccessTokensResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("access tokens revoked")
+
+	return &adminv1.RevokeAccessTokensResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) ListAuthEvents(ctx context.Context, req *adminv1.ListAuthEventsRequest) (*adminv1.ListAuthEventsResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list auth events request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionAuditRead); err != nil {
+		return nil, err
+	}
+
+	var accountID *valueobject.ID
+	if req.AccountId != "" {
+		id, err := valueobject.NewIDFromString(req.AccountId)
+		if err != nil {
+			return fail(&adminv1.ListAuthEventsResponse{
+				Success:   false,
+				ErrorCode: adminv1.ListAuthEventsResponse_INVALID_ID,
+			}, err, rpcerror.Field("account_id"))
+		}
+		accountID = &id
+		log = log.With("account_id", id.ToString())
+	}
+
+	var from, to *time.Time
+	if req.From != nil {
+		t := req.From.AsTime()
+		from = &t
+	}
+	if req.To != nil {
+		t := req.To.AsTime()
+		to = &t
+	}
+
+	events, nextPageToken, err := h.authService.ListAuthEvents(ctx, accountID, from, to, req.PageSize, req.PageToken)
+	if err != nil {
+		errorCode := adminv1.ListAuthEventsResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidTimeRange):
+			errorCode = adminv1.ListAuthEventsResponse_INVALID_TIME_RANGE
+		case errors.Is(err, appErrors.ErrInvalidPageToken):
+			errorCode = adminv1.ListAuthEventsResponse_INVALID_PAGE_TOKEN
+		default:
+			log.Error("failed at ListAuthEvents()", "error", err)
+		}
+		return fail(&adminv1.ListAuthEventsResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	res := make([]*adminv1.AuthEvent, 0, len(events))
+	for _, event := range events {
+		res = append(res, mapAuthEventToProto(event))
+	}
+
+	return &adminv1.ListAuthEventsResponse{
+		Success:       true,
+		Events:        res,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func mapAuthEventToProto(event *entities.AuthEvent) *adminv1.AuthEvent {
+	res := &adminv1.AuthEvent{
+		Id:        event.ID.ToString(),
+		EventType: string(event.Type),
+		Outcome:   string(event.Outcome),
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+	if event.AccountID != nil {
+		res.AccountId = event.AccountID.ToString()
+	}
+	if event.IdentifierHash != nil {
+		res.IdentifierHash = *event.IdentifierHash
+	}
+	if event.IP != nil {
+		res.Ip = *event.IP
+	}
+	if event.UserAgent != nil {
+		res.UserAgent = *event.UserAgent
+	}
+	if event.ClientID != nil {
+		res.ClientId = *event.ClientID
+	}
+	if event.ErrorCode != nil {
+		res.ErrorCode = *event.ErrorCode
+	}
+
+	return res
+}
+
+func toPermissions(values []string) []valueobject.Permission {
+	permissions := make([]valueobject.Permission, 0, len(values))
+	for _, value := range values {
+		permissions = append(permissions, valueobject.Permission(value))
+	}
+
+	return permissions
+}
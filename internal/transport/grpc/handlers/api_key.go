@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *AccountHandler) CreateApiKey(ctx context.Context, req *accountv1.CreateApiKeyRequest) (*accountv1.CreateApiKeyResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("create api key request received")
+
+	accountID, err := sessionAccountIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log = log.With("account_id", accountID.ToString())
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t := req.ExpiresAt.AsTime()
+		expiresAt = &t
+	}
+
+	res, err := h.authService.CreateApiKey(ctx, accountID, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidApiKeyName):
+			return sendErrorCreateApiKeyResponse(accountv1.CreateApiKeyResponse_INVALID_NAME, err, rpcerror.Field("name"))
+		case errors.Is(err, appErrors.ErrInvalidApiKeyExpiry):
+			return sendErrorCreateApiKeyResponse(accountv1.CreateApiKeyResponse_INVALID_EXPIRES_AT, err, rpcerror.Field("expires_at"))
+		case errors.Is(err, appErrors.ErrInvalidScope):
+			return sendErrorCreateApiKeyResponse(accountv1.CreateApiKeyResponse_INVALID_SCOPE, err, rpcerror.Field("scopes"))
+		}
+		log.Error("failed at CreateApiKey()", "error", err)
+		return sendErrorCreateApiKeyResponse(accountv1.CreateApiKeyResponse_INTERNAL_ERROR, err)
+	}
+
+	log.Info("api key created", "api_key_id", res.ApiKey.ID.ToString())
+
+	return &accountv1.CreateApiKeyResponse{
+		Success: true,
+		ApiKey:  mapApiKeyToProto(res.ApiKey),
+		Key:     res.Key,
+	}, nil
+}
+
+func (h *AccountHandler) ListApiKeys(ctx context.Context, _ *accountv1.ListApiKeysRequest) (*accountv1.ListApiKeysResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list api keys request received")
+
+	accountID, err := sessionAccountIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := h.authService.ListApiKeys(ctx, accountID)
+	if err != nil {
+		log.Error("failed at ListApiKeys()", "error", err)
+		return sendErrorListApiKeysResponse(accountv1.ListApiKeysResponse_INTERNAL_ERROR, err)
+	}
+
+	apiKeys := make([]*accountv1.ApiKey, 0, len(keys))
+	for _, key := range keys {
+		apiKeys = append(apiKeys, mapApiKeyToProto(key))
+	}
+
+	return &accountv1.ListApiKeysResponse{
+		Success: true,
+		ApiKeys: apiKeys,
+	}, nil
+}
+
+func (h *AccountHandler) RevokeApiKey(ctx context.Context, req *accountv1.RevokeApiKeyRequest) (*accountv1.RevokeApiKeyResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("revoke api key request received")
+
+	accountID, err := sessionAccountIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := valueobject.NewIDFromString(req.GetId())
+	if err != nil {
+		return sendErrorRevokeApiKeyResponse(accountv1.RevokeApiKeyResponse_INVALID_ID, err, rpcerror.Field("id"))
+	}
+
+	log = log.With("account_id", accountID.ToString(), "api_key_id", keyID.ToString())
+
+	err = h.authService.RevokeApiKey(ctx, accountID, keyID)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrApiKeyNotFound) {
+			return sendErrorRevokeApiKeyResponse(accountv1.RevokeApiKeyResponse_API_KEY_NOT_FOUND, err)
+		}
+		log.Error("failed at RevokeApiKey()", "error", err)
+		return sendErrorRevokeApiKeyResponse(accountv1.RevokeApiKeyResponse_INTERNAL_ERROR, err)
+	}
+
+	log.Info("api key revoked")
+
+	return &accountv1.RevokeApiKeyResponse{
+		Success: true,
+	}, nil
+}
+
+func mapApiKeyToProto(key *entities.ApiKey) *accountv1.ApiKey {
+	res := &accountv1.ApiKey{
+		Id:        key.ID.ToString(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+	if key.ExpiresAt != nil {
+		res.ExpiresAt = timestamppb.New(*key.ExpiresAt)
+	}
+	if key.LastUsedAt != nil {
+		res.LastUsedAt = timestamppb.New(*key.LastUsedAt)
+	}
+	if key.RevokedAt != nil {
+		res.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+
+	return res
+}
@@ -3,59 +3,132 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
 	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/interceptors"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
 	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
-	"github.com/teacinema-go/core/logger"
 	"github.com/teacinema-go/passport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type AuthHandler struct {
-	authService AuthService
+	authService      AuthService
+	auditRecorder    AuditRecorder
+	identifierPolicy IdentifierPolicy
+	challengeGate    ChallengeGate
 	authv1.UnimplementedAuthServiceServer
 }
 
-func NewAuthHandler(authService AuthService) *AuthHandler {
+// NewAuthHandler accepts a nil challengeGate when SendOtp is not gated.
+func NewAuthHandler(authService AuthService, auditRecorder AuditRecorder, identifierPolicy IdentifierPolicy, challengeGate ChallengeGate) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:      authService,
+		auditRecorder:    auditRecorder,
+		identifierPolicy: identifierPolicy,
+		challengeGate:    challengeGate,
 	}
 }
 
-func (h *AuthHandler) SendOtp(ctx context.Context, req *authv1.SendOtpRequest) (*authv1.SendOtpResponse, error) {
-	log := logger.With(
-		"method", "SendOtp",
-	)
+func (h *AuthHandler) SendOtp(ctx context.Context, req *authv1.SendOtpRequest) (resp *authv1.SendOtpResponse, err error) {
+	log := logging.FromContext(ctx)
 
 	log.Info("send otp request received")
 
+	event := dto.AuthEvent{
+		Type: valueobject.AuthEventTypeOtpSend,
+	}
+	defer func() {
+		h.recordAuthEvent(ctx, event, resp.GetSuccess(), resp.GetErrorCode(), err)
+	}()
+
+	client, err := h.authService.ResolveClient(ctx, clientIDFromContext(ctx), valueobject.GrantTypeOtp)
+	if err != nil {
+		log.Warn("failed at ResolveClient()", "error", err)
+		return nil, clientError(err)
+	}
+
+	log = log.With("client_id", client.ID)
+	event.ClientID = client.ID
+
 	identifierType, err := valueobject.NewIdentifierTypeFromProto(req.IdentifierType)
 	if err != nil {
-		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INVALID_IDENTIFIER_TYPE)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INVALID_IDENTIFIER_TYPE, err, rpcerror.Field("identifier_type"))
+	}
+
+	identifier, err := h.identifierPolicy.Normalize(valueobject.Identifier(req.Identifier), identifierType)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrInvalidE164Phone) || errors.Is(err, appErrors.ErrInvalidEmail) {
+			return sendErrorSendOtpResponse(authv1.SendOtpResponse_INVALID_IDENTIFIER, err, rpcerror.Field("identifier"))
+		}
+		log.Error("failed at Normalize()", "error", err)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR, err)
 	}
 
-	identifier := valueobject.Identifier(req.Identifier)
 	err = identifier.Validate(identifierType)
 	if err != nil {
-		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INVALID_IDENTIFIER)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INVALID_IDENTIFIER, err, rpcerror.Field("identifier"))
 	}
+	event.Identifier = identifier
 
 	log = log.With("identifier_type", identifierType)
 
+	if h.challengeGate != nil {
+		challenge, err := h.challengeGate.Check(ctx, dto.ChallengeRequest{
+			IP:             requestinfo.FromContext(ctx).IP,
+			Identifier:     identifier,
+			IdentifierType: identifierType,
+			Type:           valueobject.NewChallengeTypeFromProto(req.ChallengeType),
+			Response:       req.ChallengeResponse,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, appErrors.ErrChallengeRequired):
+				return sendChallengeSendOtpResponse(authv1.SendOtpResponse_CHALLENGE_REQUIRED, err, challenge)
+			case errors.Is(err, appErrors.ErrInvalidChallenge):
+				log.Warn("invalid challenge response", "error", err)
+				return sendChallengeSendOtpResponse(authv1.SendOtpResponse_INVALID_CHALLENGE, err, challenge)
+			}
+			log.Error("failed at Check()", "error", err)
+			return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR, err)
+		}
+	}
+
 	exists, err := h.authService.AccountExists(ctx, identifier, identifierType)
 	if err != nil {
 		log.Error("failed at AccountExists()", "error", err)
-		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR, err)
 	}
 
 	if exists {
-		return sendErrorSendOtpResponse(authv1.SendOtpResponse_ACCOUNT_ALREADY_EXISTS)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_ACCOUNT_ALREADY_EXISTS, appErrors.ErrAccountAlreadyExists)
+	}
+
+	err = h.identifierPolicy.Allow(ctx, identifier, identifierType)
+	if err != nil {
+		switch {
+		case errors.Is(err, appErrors.ErrPhoneNotAllowed):
+			log.Warn("phone number rejected by policy", "error", err)
+			return sendErrorSendOtpResponse(authv1.SendOtpResponse_PHONE_NOT_ALLOWED, err, rpcerror.Field("identifier"))
+		case errors.Is(err, appErrors.ErrEmailNotAllowed):
+			log.Warn("email rejected by policy", "error", err)
+			return sendErrorSendOtpResponse(authv1.SendOtpResponse_EMAIL_NOT_ALLOWED, err, rpcerror.Field("identifier"))
+		}
+		log.Error("failed at Allow()", "error", err)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR, err)
 	}
 
 	otp, err := h.authService.GenerateOtp(ctx, identifier, identifierType)
 	if err != nil {
 		log.Error("failed at GenerateOtp()", "error", err)
-		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR)
+		return sendErrorSendOtpResponse(authv1.SendOtpResponse_INTERNAL_ERROR, err)
 	}
 
 	log.Info("otp generated")
@@ -69,23 +142,46 @@ func (h *AuthHandler) SendOtp(ctx context.Context, req *authv1.SendOtpRequest) (
 	}, nil
 }
 
-func (h *AuthHandler) VerifyOtp(ctx context.Context, req *authv1.VerifyOtpRequest) (*authv1.VerifyOtpResponse, error) {
-	log := logger.With(
-		"method", "VerifyOtp",
-	)
+func (h *AuthHandler) VerifyOtp(ctx context.Context, req *authv1.VerifyOtpRequest) (resp *authv1.VerifyOtpResponse, err error) {
+	log := logging.FromContext(ctx)
 
 	log.Info("verify otp request received")
 
+	event := dto.AuthEvent{
+		Type: valueobject.AuthEventTypeOtpVerify,
+	}
+	defer func() {
+		h.recordAuthEvent(ctx, event, resp.GetSuccess(), resp.GetErrorCode(), err)
+	}()
+
+	client, err := h.authService.ResolveClient(ctx, clientIDFromContext(ctx), valueobject.GrantTypeOtp)
+	if err != nil {
+		log.Warn("failed at ResolveClient()", "error", err)
+		return nil, clientError(err)
+	}
+
+	log = log.With("client_id", client.ID)
+	event.ClientID = client.ID
+
 	identifierType, err := valueobject.NewIdentifierTypeFromProto(req.IdentifierType)
 	if err != nil {
-		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_IDENTIFIER_TYPE)
+		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_IDENTIFIER_TYPE, err, rpcerror.Field("identifier_type"))
+	}
+
+	identifier, err := h.identifierPolicy.Normalize(valueobject.Identifier(req.Identifier), identifierType)
+	if err != nil {
+		if errors.Is(err, appErrors.ErrInvalidE164Phone) || errors.Is(err, appErrors.ErrInvalidEmail) {
+			return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_IDENTIFIER, err, rpcerror.Field("identifier"))
+		}
+		log.Error("failed at Normalize()", "error", err)
+		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INTERNAL_ERROR, err)
 	}
 
-	identifier := valueobject.Identifier(req.Identifier)
 	err = identifier.Validate(identifierType)
 	if err != nil {
-		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_IDENTIFIER)
+		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_IDENTIFIER, err, rpcerror.Field("identifier"))
 	}
+	event.Identifier = identifier
 
 	log = log.With("identifier_type", identifierType)
 
@@ -93,30 +189,30 @@ func (h *AuthHandler) VerifyOtp(ctx context.Context, req *authv1.VerifyOtpReques
 	if err != nil {
 		if errors.Is(err, appErrors.ErrNotFound) {
 			log.Warn("invalid or expired otp")
-			return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_EXPIRED_OTP)
+			return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_EXPIRED_OTP, appErrors.ErrExpiredOtp)
 		}
 		log.Error("failed at VerifyOtp()", "error", err)
-		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INTERNAL_ERROR)
+		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INTERNAL_ERROR, err)
 	}
 
 	if !isValid {
 		log.Warn("invalid otp")
-		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_OTP)
+		return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_INVALID_OTP, appErrors.ErrInvalidOtp)
 	}
 
 	log.Info("otp verified")
 
-	res, err := h.authService.CreateAccountWithTokens(ctx, identifier, identifierType)
+	res, err := h.authService.CreateAccountWithTokens(ctx, client, identifier, identifierType)
 	if err != nil {
 		if errors.Is(err, appErrors.ErrAccountAlreadyExists) {
-			return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_ACCOUNT_ALREADY_EXISTS)
+			return sendErrorVerifyOtpResponse(authv1.VerifyOtpResponse_ACCOUNT_ALREADY_EXISTS, err)
 		}
 		log.Error("failed at CreateAccountWithTokens()", "error", err)
-		return &authv1.VerifyOtpResponse{
+		return fail(&authv1.VerifyOtpResponse{
 			Success:      false,
 			ErrorCode:    authv1.VerifyOtpResponse_INTERNAL_ERROR,
 			ErrorMessage: "failed to create account",
-		}, nil
+		}, err)
 	}
 
 	log.Info("verification completed")
@@ -124,67 +220,89 @@ func (h *AuthHandler) VerifyOtp(ctx context.Context, req *authv1.VerifyOtpReques
 	return &authv1.VerifyOtpResponse{
 		Success: true,
 		Tokens: &authv1.VerifyOtpResponse_AuthTokens{
-			AccessToken:      res.AccessToken,
-			RefreshToken:     res.RefreshToken,
-			ExpiresInSeconds: res.ExpiresIn,
+			AccessToken:             res.AccessToken,
+			RefreshToken:            res.RefreshToken,
+			ExpiresInSeconds:        res.ExpiresIn,
+			RefreshExpiresInSeconds: res.RefreshExpiresIn,
 		},
 	}, nil
 }
 
-func (h *AuthHandler) Refresh(ctx context.Context, req *authv1.RefreshRequest) (*authv1.RefreshResponse, error) {
-	log := logger.With(
-		"method", "Refresh",
-	)
+func (h *AuthHandler) Refresh(ctx context.Context, req *authv1.RefreshRequest) (resp *authv1.RefreshResponse, err error) {
+	log := logging.FromContext(ctx)
 
 	log.Info("refresh token request received")
 
+	event := dto.AuthEvent{
+		Type: valueobject.AuthEventTypeTokenRefresh,
+	}
+	defer func() {
+		h.recordAuthEvent(ctx, event, resp.GetSuccess(), resp.GetErrorCode(), err)
+	}()
+
+	client, err := h.authService.ResolveClient(ctx, clientIDFromContext(ctx), valueobject.GrantTypeRefreshToken)
+	if err != nil {
+		log.Warn("failed at ResolveClient()", "error", err)
+		return nil, clientError(err)
+	}
+
+	log = log.With("client_id", client.ID)
+	event.ClientID = client.ID
+
 	oldToken, err := passport.ParseToken(req.RefreshToken)
 	if err != nil {
 		log.Warn("failed at ParseToken()", "error", err)
 		errorCode := authv1.RefreshResponse_INTERNAL_ERROR
 		errorMessage := "failed to parse refresh token"
+		cause := err
 		switch {
 		case errors.Is(err, passport.ErrInvalidToken):
 			errorCode = authv1.RefreshResponse_INVALID_REFRESH_TOKEN
 			errorMessage = "invalid refresh token"
+			cause = appErrors.ErrInvalidRefreshToken
 		case errors.Is(err, passport.ErrExpiredToken):
 			errorCode = authv1.RefreshResponse_EXPIRED_REFRESH_TOKEN
 			errorMessage = "expired refresh token"
+			cause = appErrors.ErrExpiredRefreshToken
 		}
 
-		return &authv1.RefreshResponse{
+		return fail(&authv1.RefreshResponse{
 			Success:      false,
 			ErrorCode:    errorCode,
 			ErrorMessage: errorMessage,
-		}, nil
+		}, cause)
 	}
 
 	verified := h.authService.VerifyToken(oldToken)
 	if !verified {
 		log.Warn("invalid token signature")
-		return &authv1.RefreshResponse{
+		return fail(&authv1.RefreshResponse{
 			Success:      false,
 			ErrorCode:    authv1.RefreshResponse_INVALID_REFRESH_TOKEN,
 			ErrorMessage: "invalid refresh token",
-		}, nil
+		}, appErrors.ErrInvalidRefreshToken)
+	}
+
+	if accountID, err := valueobject.NewIDFromString(oldToken.UserID); err == nil {
+		event.AccountID = &accountID
 	}
 
-	res, err := h.authService.RotateRefreshToken(ctx, oldToken)
+	res, err := h.authService.RotateRefreshToken(ctx, client, oldToken)
 	if err != nil {
 		if errors.Is(err, appErrors.ErrInvalidRefreshToken) {
 			log.Warn("refresh token not found in database")
-			return &authv1.RefreshResponse{
+			return fail(&authv1.RefreshResponse{
 				Success:      false,
 				ErrorCode:    authv1.RefreshResponse_INVALID_REFRESH_TOKEN,
 				ErrorMessage: "invalid refresh token",
-			}, nil
+			}, err)
 		}
 		log.Error("failed at RotateRefreshToken()", "error", err)
-		return &authv1.RefreshResponse{
+		return fail(&authv1.RefreshResponse{
 			Success:      false,
 			ErrorCode:    authv1.RefreshResponse_INTERNAL_ERROR,
 			ErrorMessage: "failed to refresh token",
-		}, nil
+		}, err)
 	}
 
 	log.Info("tokens refreshed successfully")
@@ -192,41 +310,154 @@ func (h *AuthHandler) Refresh(ctx context.Context, req *authv1.RefreshRequest) (
 	return &authv1.RefreshResponse{
 		Success: true,
 		Tokens: &authv1.RefreshResponse_AuthTokens{
-			AccessToken:      res.AccessToken,
-			RefreshToken:     res.RefreshToken,
-			ExpiresInSeconds: res.ExpiresIn,
+			AccessToken:             res.AccessToken,
+			RefreshToken:            res.RefreshToken,
+			ExpiresInSeconds:        res.ExpiresIn,
+			RefreshExpiresInSeconds: res.RefreshExpiresIn,
 		},
 	}, nil
 }
 
-func (h *AuthHandler) Logout(ctx context.Context, req *authv1.LogoutRequest) (*authv1.LogoutResponse, error) {
-	log := logger.With(
-		"method", "Logout",
-	)
+func (h *AuthHandler) Logout(ctx context.Context, req *authv1.LogoutRequest) (resp *authv1.LogoutResponse, err error) {
+	log := logging.FromContext(ctx)
 
 	log.Info("logout request received")
 
-	err := h.authService.Logout(ctx, req.RefreshToken)
+	event := dto.AuthEvent{
+		Type: valueobject.AuthEventTypeLogout,
+	}
+	defer func() {
+		h.recordAuthEvent(ctx, event, resp.GetSuccess(), resp.GetErrorCode(), err)
+	}()
+
+	accountID, err := h.authService.Logout(ctx, req.RefreshToken)
 	if err != nil {
 		if errors.Is(err, appErrors.ErrInvalidRefreshToken) {
 			log.Warn("refresh token not found in database")
-			return &authv1.LogoutResponse{
+			return fail(&authv1.LogoutResponse{
 				Success:      false,
 				ErrorCode:    authv1.LogoutResponse_INVALID_REFRESH_TOKEN,
 				ErrorMessage: "invalid refresh token",
-			}, nil
+			}, err)
 		}
 		log.Error("failed at Logout()", "error", err)
-		return &authv1.LogoutResponse{
+		return fail(&authv1.LogoutResponse{
 			Success:      false,
 			ErrorCode:    authv1.LogoutResponse_INTERNAL_ERROR,
 			ErrorMessage: "failed to logout",
-		}, nil
+		}, err)
 	}
 
-	log.Info("logout successful")
+	event.AccountID = &accountID
+	log.Info("logout successful", "account_id", accountID.ToString())
 
 	return &authv1.LogoutResponse{
 		Success: true,
 	}, nil
 }
+
+func (h *AuthHandler) ClientCredentials(ctx context.Context, req *authv1.ClientCredentialsRequest) (resp *authv1.ClientCredentialsResponse, err error) {
+	log := logging.FromContext(ctx).With("client_id", req.ClientId)
+
+	log.Info("client credentials request received")
+
+	event := dto.AuthEvent{
+		Type:     valueobject.AuthEventTypeClientCredentials,
+		ClientID: req.ClientId,
+	}
+	defer func() {
+		h.recordAuthEvent(ctx, event, resp.GetSuccess(), resp.GetErrorCode(), err)
+	}()
+
+	res, err := h.authService.IssueClientCredentialsToken(ctx, dto.ClientCredentials{
+		ClientID:        req.ClientId,
+		ClientSecret:    req.ClientSecret,
+		ClientAssertion: req.ClientAssertion,
+		Scopes:          req.Scopes,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidClient):
+			log.Warn("client authentication failed")
+			return sendErrorClientCredentialsResponse(authv1.ClientCredentialsResponse_INVALID_CLIENT, err)
+		case errors.Is(err, appErrors.ErrGrantTypeNotAllowed):
+			log.Warn("client credentials grant not allowed")
+			return sendErrorClientCredentialsResponse(authv1.ClientCredentialsResponse_UNAUTHORIZED_CLIENT, err)
+		case errors.Is(err, appErrors.ErrInvalidScope):
+			log.Warn("requested scope not allowed")
+			return sendErrorClientCredentialsResponse(authv1.ClientCredentialsResponse_INVALID_SCOPE, err, rpcerror.Field("scopes"))
+		}
+		log.Error("failed at IssueClientCredentialsToken()", "error", err)
+		return sendErrorClientCredentialsResponse(authv1.ClientCredentialsResponse_INTERNAL_ERROR, err)
+	}
+
+	log.Info("client access token issued")
+
+	return &authv1.ClientCredentialsResponse{
+		Success: true,
+		Token: &authv1.ClientCredentialsResponse_AccessToken{
+			AccessToken:      res.AccessToken,
+			ExpiresInSeconds: res.ExpiresIn,
+			Scopes:           res.Scopes,
+		},
+	}, nil
+}
+
+// IntrospectToken lets other services check an access token, including
+// whether it was revoked. Any authenticated caller may use it.
+func (h *AuthHandler) IntrospectToken(ctx context.Context, req *authv1.IntrospectTokenRequest) (*authv1.IntrospectTokenResponse, error) {
+	log := logging.FromContext(ctx)
+
+	if _, ok := interceptors.PrincipalFromContext(ctx); !ok {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	res, err := h.authService.IntrospectToken(ctx, req.Token)
+	if err != nil {
+		log.Error("failed at IntrospectToken()", "error", err)
+		return fail(&authv1.IntrospectTokenResponse{
+			Success:      false,
+			ErrorCode:    authv1.IntrospectTokenResponse_INTERNAL_ERROR,
+			ErrorMessage: "failed to introspect token",
+		}, err)
+	}
+
+	if !res.Active {
+		return &authv1.IntrospectTokenResponse{
+			Success: true,
+		}, nil
+	}
+
+	return &authv1.IntrospectTokenResponse{
+		Success:   true,
+		Active:    true,
+		Subject:   res.Subject,
+		ClientId:  res.ClientID,
+		Scopes:    res.Scopes,
+		TokenId:   res.TokenID,
+		SessionId: res.SessionID,
+		ActorId:   res.ActorID,
+		IssuedAt:  timestamppb.New(res.IssuedAt),
+		ExpiresAt: timestamppb.New(res.ExpiresAt),
+	}, nil
+}
+
+// recordAuthEvent derives the outcome from what the caller actually received:
+// a gRPC status for transport-level rejections, otherwise the response's own
+// error code. Failures reported through fail carry a response as well, so
+// they are recorded by error code whichever form reaches the client.
+func (h *AuthHandler) recordAuthEvent(ctx context.Context, event dto.AuthEvent, success bool, errorCode fmt.Stringer, err error) {
+	var rpcErr *rpcerror.Error
+	switch {
+	case success:
+		event.Outcome = valueobject.AuthEventOutcomeSuccess
+	case err == nil, errors.As(err, &rpcErr) && rpcErr.Response != nil:
+		event.Outcome = valueobject.AuthEventOutcomeFailure
+		event.ErrorCode = errorCode.String()
+	default:
+		event.Outcome = valueobject.AuthEventOutcomeFailure
+		event.ErrorCode = status.Code(err).String()
+	}
+
+	h.auditRecorder.Record(ctx, event)
+}
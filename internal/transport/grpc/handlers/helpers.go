@@ -1,27 +1,207 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/teacinema-go/auth-service/internal/auth/dto"
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/interceptors"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
 	accountv1 "github.com/teacinema-go/contracts/gen/go/account/v1"
 	authv1 "github.com/teacinema-go/contracts/gen/go/auth/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-func sendErrorSendOtpResponse(errorCode authv1.SendOtpResponse_ErrorCode) (*authv1.SendOtpResponse, error) {
-	return &authv1.SendOtpResponse{
+const clientIDMetadataKey = "x-client-id"
+
+func clientIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(clientIDMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// sessionPrincipalFromContext guards sensitive actions: they require an
+// interactive session of the account owner, not an API key or an
+// impersonation token.
+func sessionPrincipalFromContext(ctx context.Context) (*entities.Principal, error) {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok || principal.AccountID == nil {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if principal.ApiKeyID != nil {
+		return nil, status.Error(codes.PermissionDenied, "action not allowed with an api key")
+	}
+
+	if principal.ActorID != nil {
+		return nil, status.Error(codes.PermissionDenied, "action not allowed while impersonating")
+	}
+
+	return principal, nil
+}
+
+func sessionAccountIDFromContext(ctx context.Context) (valueobject.ID, error) {
+	principal, err := sessionPrincipalFromContext(ctx)
+	if err != nil {
+		return valueobject.ID{}, err
+	}
+
+	return *principal.AccountID, nil
+}
+
+// principalAccountID returns the caller's account, or nil for callers that
+// are not accounts, such as client credentials tokens.
+func principalAccountID(ctx context.Context) *valueobject.ID {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return principal.AccountID
+}
+
+// requireAccountAccess lets callers read their own account; reading any
+// other account needs accounts:read.
+func requireAccountAccess(ctx context.Context, accountID valueobject.ID) error {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if principal.AccountID != nil && *principal.AccountID == accountID {
+		return nil
+	}
+
+	if !principal.HasPermissions(valueobject.PermissionAccountsRead) {
+		return status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+
+	return nil
+}
+
+func requirePermissions(ctx context.Context, permissions ...valueobject.Permission) error {
+	principal, ok := interceptors.PrincipalFromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if !principal.HasPermissions(permissions...) {
+		return status.Error(codes.PermissionDenied, "insufficient permissions")
+	}
+
+	return nil
+}
+
+// fail returns the legacy failure body together with an error describing
+// its cause; the Errors interceptor decides which of the two is sent.
+func fail[T any](resp T, cause error, opts ...rpcerror.Option) (T, error) {
+	return resp, rpcerror.New(resp, cause, opts...)
+}
+
+func clientError(err error) error {
+	switch {
+	case errors.Is(err, appErrors.ErrClientNotFound):
+		return status.Error(codes.Unauthenticated, "unknown client")
+	case errors.Is(err, appErrors.ErrGrantTypeNotAllowed):
+		return status.Error(codes.PermissionDenied, "grant type not allowed for client")
+	default:
+		return status.Error(codes.Internal, "failed to resolve client")
+	}
+}
+
+func sendErrorSendOtpResponse(errorCode authv1.SendOtpResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*authv1.SendOtpResponse, error) {
+	return fail(&authv1.SendOtpResponse{
+		Success:   false,
+		ErrorCode: errorCode,
+	}, cause, opts...)
+}
+
+// sendChallengeSendOtpResponse returns the challenge both in the legacy body
+// and as ErrorInfo metadata for clients reading gRPC statuses.
+func sendChallengeSendOtpResponse(errorCode authv1.SendOtpResponse_ErrorCode, cause error, challenge *dto.Challenge) (*authv1.SendOtpResponse, error) {
+	types := make([]authv1.ChallengeType, 0, len(challenge.Types))
+	names := make([]string, 0, len(challenge.Types))
+	for _, t := range challenge.Types {
+		types = append(types, t.ToProto())
+		names = append(names, string(t))
+	}
+
+	metadata := map[string]string{
+		"challenge_types": strings.Join(names, ","),
+	}
+	if challenge.CaptchaProvider != "" {
+		metadata["captcha_provider"] = challenge.CaptchaProvider
+	}
+	if challenge.PowChallenge != "" {
+		metadata["pow_challenge"] = challenge.PowChallenge
+		metadata["pow_difficulty"] = strconv.Itoa(challenge.PowDifficulty)
+	}
+
+	return fail(&authv1.SendOtpResponse{
+		Success:   false,
+		ErrorCode: errorCode,
+		Challenge: &authv1.SendOtpResponse_Challenge{
+			Types:           types,
+			CaptchaProvider: challenge.CaptchaProvider,
+			PowChallenge:    challenge.PowChallenge,
+			PowDifficulty:   int32(challenge.PowDifficulty),
+		},
+	}, cause, rpcerror.Metadata(metadata))
+}
+
+func sendErrorVerifyOtpResponse(errorCode authv1.VerifyOtpResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*authv1.VerifyOtpResponse, error) {
+	return fail(&authv1.VerifyOtpResponse{
+		Success:   false,
+		ErrorCode: errorCode,
+	}, cause, opts...)
+}
+
+func sendErrorClientCredentialsResponse(errorCode authv1.ClientCredentialsResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*authv1.ClientCredentialsResponse, error) {
+	return fail(&authv1.ClientCredentialsResponse{
+		Success:   false,
+		ErrorCode: errorCode,
+	}, cause, opts...)
+}
+
+func sendErrorGetAccountResponse(errorCode accountv1.GetAccountResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*accountv1.GetAccountResponse, error) {
+	return fail(&accountv1.GetAccountResponse{
+		Success:   false,
+		ErrorCode: errorCode,
+	}, cause, opts...)
+}
+
+func sendErrorCreateApiKeyResponse(errorCode accountv1.CreateApiKeyResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*accountv1.CreateApiKeyResponse, error) {
+	return fail(&accountv1.CreateApiKeyResponse{
 		Success:   false,
 		ErrorCode: errorCode,
-	}, nil
+	}, cause, opts...)
 }
 
-func sendErrorVerifyOtpResponse(errorCode authv1.VerifyOtpResponse_ErrorCode) (*authv1.VerifyOtpResponse, error) {
-	return &authv1.VerifyOtpResponse{
+func sendErrorListApiKeysResponse(errorCode accountv1.ListApiKeysResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*accountv1.ListApiKeysResponse, error) {
+	return fail(&accountv1.ListApiKeysResponse{
 		Success:   false,
 		ErrorCode: errorCode,
-	}, nil
+	}, cause, opts...)
 }
 
-func sendErrorGetAccountResponse(errorCode accountv1.GetAccountResponse_ErrorCode) (*accountv1.GetAccountResponse, error) {
-	return &accountv1.GetAccountResponse{
+func sendErrorRevokeApiKeyResponse(errorCode accountv1.RevokeApiKeyResponse_ErrorCode, cause error, opts ...rpcerror.Option) (*accountv1.RevokeApiKeyResponse, error) {
+	return fail(&accountv1.RevokeApiKeyResponse{
 		Success:   false,
 		ErrorCode: errorCode,
-	}, nil
+	}, cause, opts...)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"time"
 
 	"github.com/teacinema-go/auth-service/internal/auth/dto"
 	"github.com/teacinema-go/auth-service/internal/auth/entities"
@@ -11,13 +12,52 @@ import (
 
 type AuthService interface {
 	AccountExists(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (bool, error)
-	CreateAccountWithTokens(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (dto.Tokens, error)
+	ResolveClient(ctx context.Context, clientID string, grantType valueobject.GrantType) (*entities.Client, error)
+	IssueClientCredentialsToken(ctx context.Context, credentials dto.ClientCredentials) (dto.ClientAccessToken, error)
+
+	CreateAccountWithTokens(ctx context.Context, client *entities.Client, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (dto.Tokens, error)
 	GetAccount(ctx context.Context, accountID valueobject.ID) (*entities.Account, error)
 
+	CreateApiKey(ctx context.Context, accountID valueobject.ID, name string, scopes []string, expiresAt *time.Time) (dto.CreatedApiKey, error)
+	ListApiKeys(ctx context.Context, accountID valueobject.ID) ([]*entities.ApiKey, error)
+	RevokeApiKey(ctx context.Context, accountID valueobject.ID, keyID valueobject.ID) error
+
 	GenerateOtp(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (string, error)
 	VerifyOtp(ctx context.Context, otp string, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (bool, error)
 
+	ListRoles(ctx context.Context) ([]*entities.Role, error)
+	ListPermissions(ctx context.Context) ([]*entities.Permission, error)
+	CreateRole(ctx context.Context, role valueobject.Role, description string, permissions []valueobject.Permission) error
+	DeleteRole(ctx context.Context, role valueobject.Role) error
+	SetRolePermissions(ctx context.Context, actorID *valueobject.ID, role valueobject.Role, permissions []valueobject.Permission) error
+	SetAccountRole(ctx context.Context, actorID *valueobject.ID, accountID valueobject.ID, role valueobject.Role) error
+	Impersonate(ctx context.Context, actorID valueobject.ID, clientID string, targetID valueobject.ID, reason string) (dto.ImpersonationToken, error)
+	ListAuthEvents(ctx context.Context, accountID *valueobject.ID, from *time.Time, to *time.Time, pageSize int32, pageToken string) ([]*entities.AuthEvent, string, error)
+
+	CreateWebhookSubscription(ctx context.Context, rawURL string, eventTypes []string) (dto.CreatedWebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*entities.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, subscriptionID valueobject.ID) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID valueobject.ID, status *valueobject.WebhookDeliveryStatus, limit int32) ([]*entities.WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID valueobject.ID) ([]*entities.WebhookDeliveryAttempt, error)
+	ReplayWebhookDelivery(ctx context.Context, deliveryID valueobject.ID) error
+
 	VerifyToken(token *passport.Token) bool
-	RotateRefreshToken(ctx context.Context, oldToken *passport.Token) (dto.Tokens, error)
-	Logout(ctx context.Context, refreshToken string) error
+	RotateRefreshToken(ctx context.Context, client *entities.Client, oldToken *passport.Token) (dto.Tokens, error)
+	Logout(ctx context.Context, refreshToken string) (valueobject.ID, error)
+	IntrospectToken(ctx context.Context, accessToken string) (dto.TokenIntrospection, error)
+	RevokeAccountTokens(ctx context.Context, accountID valueobject.ID) error
+	RevokeAccessToken(ctx context.Context, accountID valueobject.ID, tokenID valueobject.ID) error
+}
+
+type AuditRecorder interface {
+	Record(ctx context.Context, event dto.AuthEvent)
+}
+
+type IdentifierPolicy interface {
+	Normalize(identifier valueobject.Identifier, identifierType valueobject.IdentifierType) (valueobject.Identifier, error)
+	Allow(ctx context.Context, identifier valueobject.Identifier, identifierType valueobject.IdentifierType) error
+}
+
+type ChallengeGate interface {
+	Check(ctx context.Context, req dto.ChallengeRequest) (*dto.Challenge, error)
 }
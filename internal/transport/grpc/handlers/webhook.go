@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	"github.com/teacinema-go/auth-service/internal/auth/valueobject"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	adminv1 "github.com/teacinema-go/contracts/gen/go/admin/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *AdminHandler) CreateWebhookSubscription(ctx context.Context, req *adminv1.CreateWebhookSubscriptionRequest) (*adminv1.CreateWebhookSubscriptionResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("create webhook subscription request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksWrite); err != nil {
+		return nil, err
+	}
+
+	res, err := h.authService.CreateWebhookSubscription(ctx, req.Url, req.EventTypes)
+	if err != nil {
+		errorCode := adminv1.CreateWebhookSubscriptionResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidWebhookURL):
+			errorCode = adminv1.CreateWebhookSubscriptionResponse_INVALID_URL
+		case errors.Is(err, appErrors.ErrInvalidEventType):
+			errorCode = adminv1.CreateWebhookSubscriptionResponse_INVALID_EVENT_TYPE
+		default:
+			log.Error("failed at CreateWebhookSubscription()", "error", err)
+		}
+		return fail(&adminv1.CreateWebhookSubscriptionResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("webhook subscription created", "subscription_id", res.Subscription.ID.ToString())
+
+	return &adminv1.CreateWebhookSubscriptionResponse{
+		Success:      true,
+		Subscription: mapWebhookSubscriptionToProto(res.Subscription),
+		Secret:       res.Secret,
+	}, nil
+}
+
+func (h *AdminHandler) ListWebhookSubscriptions(ctx context.Context, _ *adminv1.ListWebhookSubscriptionsRequest) (*adminv1.ListWebhookSubscriptionsResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list webhook subscriptions request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksRead); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := h.authService.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		log.Error("failed at ListWebhookSubscriptions()", "error", err)
+		return fail(&adminv1.ListWebhookSubscriptionsResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListWebhookSubscriptionsResponse_INTERNAL_ERROR,
+		}, err)
+	}
+
+	res := make([]*adminv1.WebhookSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		res = append(res, mapWebhookSubscriptionToProto(subscription))
+	}
+
+	return &adminv1.ListWebhookSubscriptionsResponse{
+		Success:       true,
+		Subscriptions: res,
+	}, nil
+}
+
+func (h *AdminHandler) DeleteWebhookSubscription(ctx context.Context, req *adminv1.DeleteWebhookSubscriptionRequest) (*adminv1.DeleteWebhookSubscriptionResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("delete webhook subscription request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksWrite); err != nil {
+		return nil, err
+	}
+
+	subscriptionID, err := valueobject.NewIDFromString(req.Id)
+	if err != nil {
+		return fail(&adminv1.DeleteWebhookSubscriptionResponse{
+			Success:   false,
+			ErrorCode: adminv1.DeleteWebhookSubscriptionResponse_INVALID_ID,
+		}, err, rpcerror.Field("id"))
+	}
+
+	log = log.With("subscription_id", subscriptionID.ToString())
+
+	err = h.authService.DeleteWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		errorCode := adminv1.DeleteWebhookSubscriptionResponse_INTERNAL_ERROR
+		if errors.Is(err, appErrors.ErrWebhookNotFound) {
+			errorCode = adminv1.DeleteWebhookSubscriptionResponse_WEBHOOK_NOT_FOUND
+		} else {
+			log.Error("failed at DeleteWebhookSubscription()", "error", err)
+		}
+		return fail(&adminv1.DeleteWebhookSubscriptionResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("webhook subscription deleted")
+
+	return &adminv1.DeleteWebhookSubscriptionResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AdminHandler) ListWebhookDeliveries(ctx context.Context, req *adminv1.ListWebhookDeliveriesRequest) (*adminv1.ListWebhookDeliveriesResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list webhook deliveries request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksRead); err != nil {
+		return nil, err
+	}
+
+	subscriptionID, err := valueobject.NewIDFromString(req.SubscriptionId)
+	if err != nil {
+		return fail(&adminv1.ListWebhookDeliveriesResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListWebhookDeliveriesResponse_INVALID_ID,
+		}, err, rpcerror.Field("subscription_id"))
+	}
+
+	log = log.With("subscription_id", subscriptionID.ToString())
+
+	var status *valueobject.WebhookDeliveryStatus
+	if req.Status != "" {
+		s := valueobject.WebhookDeliveryStatus(req.Status)
+		status = &s
+	}
+
+	deliveries, err := h.authService.ListWebhookDeliveries(ctx, subscriptionID, status, req.PageSize)
+	if err != nil {
+		errorCode := adminv1.ListWebhookDeliveriesResponse_INTERNAL_ERROR
+		switch {
+		case errors.Is(err, appErrors.ErrInvalidDeliveryStatus):
+			errorCode = adminv1.ListWebhookDeliveriesResponse_INVALID_STATUS
+		case errors.Is(err, appErrors.ErrWebhookNotFound):
+			errorCode = adminv1.ListWebhookDeliveriesResponse_WEBHOOK_NOT_FOUND
+		default:
+			log.Error("failed at ListWebhookDeliveries()", "error", err)
+		}
+		return fail(&adminv1.ListWebhookDeliveriesResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	res := make([]*adminv1.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		res = append(res, mapWebhookDeliveryToProto(delivery))
+	}
+
+	return &adminv1.ListWebhookDeliveriesResponse{
+		Success:    true,
+		Deliveries: res,
+	}, nil
+}
+
+func (h *AdminHandler) ListWebhookDeliveryAttempts(ctx context.Context, req *adminv1.ListWebhookDeliveryAttemptsRequest) (*adminv1.ListWebhookDeliveryAttemptsResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("list webhook delivery attempts request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksRead); err != nil {
+		return nil, err
+	}
+
+	deliveryID, err := valueobject.NewIDFromString(req.DeliveryId)
+	if err != nil {
+		return fail(&adminv1.ListWebhookDeliveryAttemptsResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListWebhookDeliveryAttemptsResponse_INVALID_ID,
+		}, err, rpcerror.Field("delivery_id"))
+	}
+
+	attempts, err := h.authService.ListWebhookDeliveryAttempts(ctx, deliveryID)
+	if err != nil {
+		log.Error("failed at ListWebhookDeliveryAttempts()", "delivery_id", deliveryID.ToString(), "error", err)
+		return fail(&adminv1.ListWebhookDeliveryAttemptsResponse{
+			Success:   false,
+			ErrorCode: adminv1.ListWebhookDeliveryAttemptsResponse_INTERNAL_ERROR,
+		}, err)
+	}
+
+	res := make([]*adminv1.WebhookDeliveryAttempt, 0, len(attempts))
+	for _, attempt := range attempts {
+		item := &adminv1.WebhookDeliveryAttempt{
+			Id:         attempt.ID.ToString(),
+			DurationMs: attempt.Duration.Milliseconds(),
+			CreatedAt:  timestamppb.New(attempt.CreatedAt),
+		}
+		if attempt.StatusCode != nil {
+			item.StatusCode = *attempt.StatusCode
+		}
+		if attempt.Error != nil {
+			item.Error = *attempt.Error
+		}
+		res = append(res, item)
+	}
+
+	return &adminv1.ListWebhookDeliveryAttemptsResponse{
+		Success:  true,
+		Attempts: res,
+	}, nil
+}
+
+func (h *AdminHandler) ReplayWebhookDelivery(ctx context.Context, req *adminv1.ReplayWebhookDeliveryRequest) (*adminv1.ReplayWebhookDeliveryResponse, error) {
+	log := logging.FromContext(ctx)
+
+	log.Info("replay webhook delivery request received")
+
+	if err := requirePermissions(ctx, valueobject.PermissionWebhooksWrite); err != nil {
+		return nil, err
+	}
+
+	deliveryID, err := valueobject.NewIDFromString(req.Id)
+	if err != nil {
+		return fail(&adminv1.ReplayWebhookDeliveryResponse{
+			Success:   false,
+			ErrorCode: adminv1.ReplayWebhookDeliveryResponse_INVALID_ID,
+		}, err, rpcerror.Field("id"))
+	}
+
+	log = log.With("delivery_id", deliveryID.ToString())
+
+	err = h.authService.ReplayWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		errorCode := adminv1.ReplayWebhookDeliveryResponse_INTERNAL_ERROR
+		if errors.Is(err, appErrors.ErrDeliveryNotFound) {
+			errorCode = adminv1.ReplayWebhookDeliveryResponse_DELIVERY_NOT_FOUND
+		} else {
+			log.Error("failed at ReplayWebhookDelivery()", "error", err)
+		}
+		return fail(&adminv1.ReplayWebhookDeliveryResponse{
+			Success:   false,
+			ErrorCode: errorCode,
+		}, err)
+	}
+
+	log.Info("webhook delivery scheduled for replay")
+
+	return &adminv1.ReplayWebhookDeliveryResponse{
+		Success: true,
+	}, nil
+}
+
+func mapWebhookSubscriptionToProto(subscription *entities.WebhookSubscription) *adminv1.WebhookSubscription {
+	return &adminv1.WebhookSubscription{
+		Id:         subscription.ID.ToString(),
+		Url:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  timestamppb.New(subscription.CreatedAt),
+	}
+}
+
+func mapWebhookDeliveryToProto(delivery *entities.WebhookDelivery) *adminv1.WebhookDelivery {
+	res := &adminv1.WebhookDelivery{
+		Id:             delivery.ID.ToString(),
+		SubscriptionId: delivery.SubscriptionID.ToString(),
+		EventId:        delivery.EventID.ToString(),
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  timestamppb.New(delivery.NextAttemptAt),
+		CreatedAt:      timestamppb.New(delivery.CreatedAt),
+	}
+	if delivery.LastStatusCode != nil {
+		res.LastStatusCode = *delivery.LastStatusCode
+	}
+	if delivery.LastError != nil {
+		res.LastError = *delivery.LastError
+	}
+	if delivery.DeliveredAt != nil {
+		res.DeliveredAt = timestamppb.New(*delivery.DeliveredAt)
+	}
+
+	return res
+}
@@ -0,0 +1,92 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/teacinema-go/auth-service/internal/auth/entities"
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/core/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationMetadataKey = "authorization"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*entities.Principal, error)
+}
+
+type principalKey struct{}
+
+func PrincipalFromContext(ctx context.Context) (*entities.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*entities.Principal)
+	return principal, ok
+}
+
+// Auth resolves the caller from the authorization metadata, which carries
+// either a bearer access token or an API key. Requests without credentials are
+// passed through unauthenticated; handlers decide whether a principal is required.
+// Public methods are never authenticated, so a stale token cannot block them.
+func Auth(authenticator Authenticator, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]struct{}, len(publicMethods))
+	for _, method := range publicMethods {
+		public[method] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		credential, ok, err := credentialFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if err != nil {
+			switch {
+			case errors.Is(err, appErrors.ErrExpiredAccessToken):
+				return nil, status.Error(codes.Unauthenticated, "expired access token")
+			case errors.Is(err, appErrors.ErrRevokedAccessToken):
+				return nil, status.Error(codes.Unauthenticated, "revoked access token")
+			case errors.Is(err, appErrors.ErrInvalidAccessToken), errors.Is(err, appErrors.ErrInvalidApiKey):
+				return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+			case errors.Is(err, appErrors.ErrRevocationUnavailable):
+				logger.Error("failed at Authenticate()", "method", info.FullMethod, "error", err)
+				return nil, status.Error(codes.Unavailable, "revocation list unavailable")
+			}
+			logger.Error("failed at Authenticate()", "method", info.FullMethod, "error", err)
+			return nil, status.Error(codes.Internal, "failed to authenticate")
+		}
+
+		return handler(context.WithValue(ctx, principalKey{}, principal), req)
+	}
+}
+
+func credentialFromContext(ctx context.Context) (string, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false, nil
+	}
+
+	values := md.Get(authorizationMetadataKey)
+	if len(values) == 0 {
+		return "", false, nil
+	}
+
+	scheme, credential, found := strings.Cut(values[0], " ")
+	credential = strings.TrimSpace(credential)
+	if !found || !strings.EqualFold(scheme, "bearer") || credential == "" {
+		return "", false, status.Error(codes.Unauthenticated, "invalid authorization header")
+	}
+
+	return credential, true, nil
+}
@@ -0,0 +1,24 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Chain composes interceptors in the order grpc.ChainUnaryInterceptor does,
+// so in-process callers such as the HTTP gateway run the same chain as the
+// gRPC server.
+func Chain(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, wrapped := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, wrapped)
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
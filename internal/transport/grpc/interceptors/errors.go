@@ -0,0 +1,30 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	"google.golang.org/grpc"
+)
+
+// Errors settles how handler failures reach the client. In compatibility
+// mode the legacy Success/ErrorCode body is sent with an OK status, as
+// existing clients expect; otherwise the call fails with a status carrying
+// errdetails.
+func Errors(compat bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+
+		var rpcErr *rpcerror.Error
+		if !errors.As(err, &rpcErr) {
+			return resp, err
+		}
+
+		if compat && rpcErr.Response != nil {
+			return rpcErr.Response, nil
+		}
+
+		return nil, rpcErr.GRPCStatus().Err()
+	}
+}
@@ -0,0 +1,38 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/infra/tracing"
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	"github.com/teacinema-go/core/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Logging attaches a logger carrying the method, peer and request ID to the
+// context for handlers, and logs the outcome of every call. It must run after
+// RequestInfo.
+func Logging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		startedAt := time.Now()
+		reqInfo := requestinfo.FromContext(ctx)
+
+		log := logger.With(tracing.LogFields(ctx,
+			"method", info.FullMethod,
+			"peer", reqInfo.IP,
+			"request_id", reqInfo.RequestID,
+		)...)
+
+		resp, err := handler(logging.NewContext(ctx, log), req)
+
+		log.Info("request completed",
+			"code", status.Code(err).String(),
+			"duration", time.Since(startedAt),
+		)
+
+		return resp, err
+	}
+}
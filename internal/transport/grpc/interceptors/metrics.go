@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const errorCodeField = "error_code"
+
+type RPCMetrics interface {
+	ObserveRPC(method string, code string, errorCode string, duration time.Duration)
+}
+
+// Metrics records every unary call. In compatibility mode failures are
+// reported in the response body with an OK status, so the error_code field of
+// the response, or else the ErrorInfo reason, is recorded next to the gRPC
+// status code.
+func Metrics(metrics RPCMetrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		startedAt := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		errorCode := responseErrorCode(resp)
+		if errorCode == "" {
+			errorCode = rpcerror.Reason(err)
+		}
+
+		metrics.ObserveRPC(info.FullMethod, status.Code(err).String(), errorCode, time.Since(startedAt))
+
+		return resp, err
+	}
+}
+
+func responseErrorCode(resp any) string {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return ""
+	}
+
+	m := msg.ProtoReflect()
+	if !m.IsValid() {
+		return ""
+	}
+
+	field := m.Descriptor().Fields().ByName(errorCodeField)
+	if field == nil || field.Enum() == nil || !m.Has(field) {
+		return ""
+	}
+
+	value := field.Enum().Values().ByNumber(m.Get(field).Enum())
+	if value == nil {
+		return ""
+	}
+
+	return string(value.Name())
+}
@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	"github.com/teacinema-go/auth-service/internal/services/ratelimit"
+	"github.com/teacinema-go/auth-service/internal/transport/grpc/rpcerror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RateLimiter interface {
+	Allow(ctx context.Context, fullMethod string, caller ratelimit.Caller) (bool, time.Duration, error)
+}
+
+// RateLimit rejects calls over the configured limits with ResourceExhausted
+// and a RetryInfo telling the client when to come back. It must run after
+// RequestInfo, which resolves the peer IP, and sees the authenticated
+// client when it runs after Auth.
+func RateLimit(limiter RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		caller := ratelimit.Caller{
+			IP: requestinfo.FromContext(ctx).IP,
+		}
+		if principal, ok := PrincipalFromContext(ctx); ok {
+			caller.ClientID = principal.ClientID
+			if caller.ClientID == "" {
+				caller.ClientID = principal.Subject
+			}
+		}
+
+		allowed, retryAfter, err := limiter.Allow(ctx, info.FullMethod, caller)
+		if err != nil {
+			logging.FromContext(ctx).Error("failed at Allow()", "error", err)
+			return nil, status.Error(codes.Unavailable, "rate limiter unavailable")
+		}
+
+		if !allowed {
+			logging.FromContext(ctx).Warn("rate limit exceeded", "retry_after", retryAfter)
+			return nil, rpcerror.New(nil, appErrors.ErrRateLimited, rpcerror.RetryAfter(retryAfter))
+		}
+
+		return handler(ctx, req)
+	}
+}
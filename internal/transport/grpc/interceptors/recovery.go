@@ -0,0 +1,30 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/teacinema-go/auth-service/internal/infra/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Recovery turns a panicking handler into a codes.Internal error instead of
+// crashing the process. It runs after Logging so the stack is logged with the
+// request's fields.
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logging.FromContext(ctx).Error("panic in handler",
+					"panic", r,
+					"stack", string(debug.Stack()),
+				)
+				resp, err = nil, status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
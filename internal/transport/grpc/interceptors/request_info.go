@@ -0,0 +1,87 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+
+	"github.com/teacinema-go/auth-service/internal/requestinfo"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const userAgentMetadataKey = "user-agent"
+
+// RequestInfo captures who is calling and under which request ID. The ID is
+// taken from the x-request-id metadata or generated, and echoed back in the
+// response headers so clients can correlate logs.
+func RequestInfo() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := requestinfo.RequestIDOrNew(metadataValue(ctx, requestinfo.RequestIDHeader))
+
+		// Fails for in-process calls from the HTTP gateway, which sets the
+		// header itself.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestinfo.RequestIDHeader, requestID))
+
+		return handler(requestinfo.NewContext(ctx, requestinfo.Info{
+			RequestID: requestID,
+			IP:        peerIP(ctx),
+			UserAgent: metadataValue(ctx, userAgentMetadataKey),
+			PeerSANs:  peerSANs(ctx),
+		}), req)
+	}
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+func peerSANs(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	sans := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses))
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return sans
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,221 @@
+package rpcerror
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	appErrors "github.com/teacinema-go/auth-service/internal/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain identifies this service in ErrorInfo details.
+const Domain = "auth.teacinema.com"
+
+const (
+	reasonInternal    = "INTERNAL_ERROR"
+	defaultRetryDelay = time.Second
+)
+
+type mapping struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+// mappings is ordered: the first sentinel matched with errors.Is wins, so
+// the generic ErrNotFound stays last among the application errors.
+var mappings = []mapping{
+	{appErrors.ErrInvalidID, codes.InvalidArgument, "INVALID_ID"},
+	{appErrors.ErrInvalidEmail, codes.InvalidArgument, "INVALID_IDENTIFIER"},
+	{appErrors.ErrInvalidE164Phone, codes.InvalidArgument, "INVALID_IDENTIFIER"},
+	{appErrors.ErrInvalidIdentifierType, codes.InvalidArgument, "INVALID_IDENTIFIER_TYPE"},
+	{appErrors.ErrInvalidOtp, codes.Unauthenticated, "INVALID_OTP"},
+	{appErrors.ErrExpiredOtp, codes.Unauthenticated, "EXPIRED_OTP"},
+	{appErrors.ErrInvalidRefreshToken, codes.Unauthenticated, "INVALID_REFRESH_TOKEN"},
+	{appErrors.ErrExpiredRefreshToken, codes.Unauthenticated, "EXPIRED_REFRESH_TOKEN"},
+	{appErrors.ErrRefreshTokenNotFound, codes.Unauthenticated, "INVALID_REFRESH_TOKEN"},
+	{appErrors.ErrInvalidAccessToken, codes.Unauthenticated, "INVALID_ACCESS_TOKEN"},
+	{appErrors.ErrExpiredAccessToken, codes.Unauthenticated, "EXPIRED_ACCESS_TOKEN"},
+	{appErrors.ErrRevokedAccessToken, codes.Unauthenticated, "REVOKED_ACCESS_TOKEN"},
+	{appErrors.ErrRevocationUnavailable, codes.Unavailable, "REVOCATION_UNAVAILABLE"},
+	{appErrors.ErrInvalidApiKey, codes.Unauthenticated, "INVALID_API_KEY"},
+	{appErrors.ErrAccountNotFound, codes.NotFound, "ACCOUNT_NOT_FOUND"},
+	{appErrors.ErrAccountAlreadyExists, codes.AlreadyExists, "ACCOUNT_ALREADY_EXISTS"},
+	{appErrors.ErrInvalidRole, codes.InvalidArgument, "INVALID_ROLE"},
+	{appErrors.ErrRoleNotFound, codes.NotFound, "ROLE_NOT_FOUND"},
+	{appErrors.ErrRoleAlreadyExists, codes.AlreadyExists, "ROLE_ALREADY_EXISTS"},
+	{appErrors.ErrRoleInUse, codes.FailedPrecondition, "ROLE_IN_USE"},
+	{appErrors.ErrRoleLockout, codes.FailedPrecondition, "ROLE_LOCKOUT"},
+	{appErrors.ErrBuiltinRole, codes.FailedPrecondition, "BUILTIN_ROLE"},
+	{appErrors.ErrInvalidPermission, codes.InvalidArgument, "INVALID_PERMISSION"},
+	{appErrors.ErrInvalidClientType, codes.InvalidArgument, "INVALID_CLIENT_TYPE"},
+	{appErrors.ErrInvalidGrantType, codes.InvalidArgument, "INVALID_GRANT_TYPE"},
+	{appErrors.ErrClientNotFound, codes.Unauthenticated, "UNKNOWN_CLIENT"},
+	{appErrors.ErrGrantTypeNotAllowed, codes.PermissionDenied, "UNAUTHORIZED_CLIENT"},
+	{appErrors.ErrInvalidClient, codes.Unauthenticated, "INVALID_CLIENT"},
+	{appErrors.ErrInvalidScope, codes.InvalidArgument, "INVALID_SCOPE"},
+	{appErrors.ErrInvalidApiKeyName, codes.InvalidArgument, "INVALID_API_KEY_NAME"},
+	{appErrors.ErrInvalidApiKeyExpiry, codes.InvalidArgument, "INVALID_API_KEY_EXPIRY"},
+	{appErrors.ErrApiKeyNotFound, codes.NotFound, "API_KEY_NOT_FOUND"},
+	{appErrors.ErrInvalidReason, codes.InvalidArgument, "INVALID_REASON"},
+	{appErrors.ErrInvalidImpersonation, codes.FailedPrecondition, "INVALID_IMPERSONATION_TARGET"},
+	{appErrors.ErrInvalidPageToken, codes.InvalidArgument, "INVALID_PAGE_TOKEN"},
+	{appErrors.ErrInvalidTimeRange, codes.InvalidArgument, "INVALID_TIME_RANGE"},
+	{appErrors.ErrInvalidWebhookURL, codes.InvalidArgument, "INVALID_WEBHOOK_URL"},
+	{appErrors.ErrInvalidEventType, codes.InvalidArgument, "INVALID_EVENT_TYPE"},
+	{appErrors.ErrInvalidDeliveryStatus, codes.InvalidArgument, "INVALID_DELIVERY_STATUS"},
+	{appErrors.ErrWebhookNotFound, codes.NotFound, "WEBHOOK_NOT_FOUND"},
+	{appErrors.ErrDeliveryNotFound, codes.NotFound, "DELIVERY_NOT_FOUND"},
+	{appErrors.ErrRateLimited, codes.ResourceExhausted, "RATE_LIMITED"},
+	{appErrors.ErrChallengeRequired, codes.FailedPrecondition, "CHALLENGE_REQUIRED"},
+	{appErrors.ErrInvalidChallenge, codes.PermissionDenied, "INVALID_CHALLENGE"},
+	{appErrors.ErrPhoneNotAllowed, codes.InvalidArgument, "PHONE_NOT_ALLOWED"},
+	{appErrors.ErrEmailNotAllowed, codes.InvalidArgument, "EMAIL_NOT_ALLOWED"},
+	{appErrors.ErrNotFound, codes.NotFound, "NOT_FOUND"},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, "DEADLINE_EXCEEDED"},
+	{context.Canceled, codes.Canceled, "CANCELED"},
+}
+
+// Error describes a failed call in both shapes clients may expect: the
+// legacy response body with Success set to false, and a gRPC status built
+// from the application error that caused it. The Errors interceptor decides
+// which one is sent; on its own the error converts to the status.
+type Error struct {
+	Response   any
+	cause      error
+	field      string
+	retryDelay time.Duration
+	metadata   map[string]string
+}
+
+type Option func(*Error)
+
+// Field reports the request field that failed validation as a BadRequest
+// field violation.
+func Field(name string) Option {
+	return func(e *Error) {
+		e.field = name
+	}
+}
+
+// RetryAfter attaches RetryInfo telling clients when to try again.
+func RetryAfter(delay time.Duration) Option {
+	return func(e *Error) {
+		e.retryDelay = delay
+	}
+}
+
+// Metadata adds key/value context to the ErrorInfo, such as the parameters
+// of a challenge the client has to solve.
+func Metadata(metadata map[string]string) Option {
+	return func(e *Error) {
+		e.metadata = metadata
+	}
+}
+
+func New(resp any, cause error, opts ...Option) *Error {
+	e := &Error{
+		Response: resp,
+		cause:    cause,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.GRPCStatus().Message()
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) GRPCStatus() *status.Status {
+	code, reason, message := codes.Internal, reasonInternal, "internal error"
+	for _, m := range mappings {
+		if errors.Is(e.cause, m.err) {
+			code, reason, message = m.code, m.reason, m.err.Error()
+			break
+		}
+	}
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: e.metadata},
+	}
+	if e.field != "" && code == codes.InvalidArgument {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: e.field, Description: message},
+			},
+		})
+	}
+
+	retryDelay := e.retryDelay
+	if retryDelay == 0 && (code == codes.Unavailable || code == codes.Aborted) {
+		retryDelay = defaultRetryDelay
+	}
+	if retryDelay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+
+	st := status.New(code, message)
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+
+	return st
+}
+
+// Reason returns the ErrorInfo reason carried by err, or an empty string
+// when err has none.
+func Reason(err error) string {
+	if info, ok := detail[*errdetails.ErrorInfo](err); ok {
+		return info.GetReason()
+	}
+
+	return ""
+}
+
+// ErrorMetadata returns the ErrorInfo metadata carried by err.
+func ErrorMetadata(err error) map[string]string {
+	if info, ok := detail[*errdetails.ErrorInfo](err); ok {
+		return info.GetMetadata()
+	}
+
+	return nil
+}
+
+// RetryDelay returns the RetryInfo delay carried by err, or zero when err
+// has none.
+func RetryDelay(err error) time.Duration {
+	if info, ok := detail[*errdetails.RetryInfo](err); ok {
+		return info.GetRetryDelay().AsDuration()
+	}
+
+	return 0
+}
+
+func detail[T any](err error) (T, bool) {
+	var zero T
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return zero, false
+	}
+
+	for _, d := range st.Details() {
+		if v, ok := d.(T); ok {
+			return v, true
+		}
+	}
+
+	return zero, false
+}
@@ -5,6 +5,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"math/big"
+	mathrand "math/rand/v2"
+	"net/netip"
+	"time"
 )
 
 func Generate6Digit() (string, error) {
@@ -27,7 +30,70 @@ func GenerateNDigit(length int) (string, error) {
 	return string(code), nil
 }
 
+func GenerateRandomHex(nBytes int) (string, error) {
+	buf := make([]byte, nBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
 func GenerateHash(data string) string {
 	hash := sha256.Sum256([]byte(data))
 	return hex.EncodeToString(hash[:])
 }
+
+// Backoff returns an exponential delay for the given attempt, capped at
+// maxDelay, with equal jitter so retrying workers do not synchronize. The
+// delay stops doubling once it reaches maxDelay, so large attempt counts
+// cannot overflow it.
+func Backoff(attempt int32, baseDelay time.Duration, maxDelay time.Duration) time.Duration {
+	delay := min(baseDelay, maxDelay)
+	for i := int32(0); i < attempt && delay > 0; i++ {
+		if delay > maxDelay/2 {
+			delay = maxDelay
+			break
+		}
+		delay *= 2
+	}
+	delay = max(delay, 0)
+
+	return delay/2 + mathrand.N(delay/2+1)
+}
+
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// IsPublicIP reports whether addr is a globally routable unicast address.
+// Loopback, private, link-local (which holds the 169.254.169.254 metadata
+// endpoint), shared, documentation and other reserved ranges are not, nor
+// are IPv6 translation prefixes that can embed such an IPv4 address.
+func IsPublicIP(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
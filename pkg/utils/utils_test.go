@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"math"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int32
+		base    time.Duration
+		max     time.Duration
+		want    time.Duration
+	}{
+		{attempt: 0, base: time.Second, max: time.Minute, want: time.Second},
+		{attempt: 3, base: time.Second, max: time.Minute, want: 8 * time.Second},
+		{attempt: 6, base: time.Second, max: time.Minute, want: time.Minute},
+		{attempt: 40, base: 5 * time.Second, max: time.Hour, want: time.Hour},
+		{attempt: math.MaxInt32, base: time.Second, max: time.Hour, want: time.Hour},
+		{attempt: 70, base: time.Second, max: math.MaxInt64, want: math.MaxInt64},
+		{attempt: -1, base: time.Second, max: time.Minute, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			got := Backoff(tt.attempt, tt.base, tt.max)
+			if got < tt.want/2 || got > tt.want {
+				t.Fatalf("Backoff(%d, %s, %s) = %s, want within [%s, %s]", tt.attempt, tt.base, tt.max, got, tt.want/2, tt.want)
+			}
+		}
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.215.14":          true,
+		"2606:2800:21f:cb07::1":  true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"255.255.255.255":        false,
+		"224.0.0.1":              false,
+		"::1":                    false,
+		"::":                     false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"64:ff9b::a9fe:a9fe":     false,
+		"2002:a9fe:a9fe::1":      false,
+	}
+
+	for addr, want := range tests {
+		if got := IsPublicIP(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}